@@ -0,0 +1,126 @@
+package ascon
+
+import "io"
+
+// SealWriterTo is like Seal, except that the additional data is
+// written by ad instead of being provided as a slice.
+//
+// The additional data is absorbed as it is written, so it is
+// never buffered in its entirety. Any error returned by
+// ad.WriteTo is returned as-is.
+func (a *AEAD) SealWriterTo(dst, nonce, plaintext []byte, ad io.WriterTo) ([]byte, error) {
+	checkNonce(nonce)
+
+	var s state
+	a.init(&s, nonce)
+	w := adWriter{s: &s, iv: a.iv}
+	n, err := ad.WriteTo(&w)
+	if err != nil {
+		return nil, err
+	}
+	w.finish()
+	if a.count(uint64(n), uint64(len(plaintext))) {
+		panic("ascon: key usage limit exceeded")
+	}
+	return a.seal(&s, dst, plaintext, uint64(n)), nil
+}
+
+// OpenWriterTo is like Open, except that the additional data is
+// written by ad instead of being provided as a slice.
+//
+// The additional data is absorbed as it is written, so it is
+// never buffered in its entirety. Any error returned by
+// ad.WriteTo is returned as-is.
+func (a *AEAD) OpenWriterTo(dst, nonce, ciphertext []byte, ad io.WriterTo) ([]byte, error) {
+	checkNonce(nonce)
+	if len(ciphertext) < TagSize {
+		return nil, errOpen
+	}
+
+	var s state
+	a.init(&s, nonce)
+	w := adWriter{s: &s, iv: a.iv}
+	n, err := ad.WriteTo(&w)
+	if err != nil {
+		return nil, err
+	}
+	w.finish()
+	a.count(uint64(n), uint64(len(ciphertext)-TagSize))
+	return a.open(&s, dst, ciphertext, uint64(n))
+}
+
+// adWriter incrementally absorbs additional data.
+//
+// Full blocks are absorbed as soon as they're written. A partial
+// block is buffered until it is either filled or finish is
+// called.
+type adWriter struct {
+	s  *state
+	iv uint64
+	// buf is the current partial block.
+	buf [BlockSize128a]byte
+	// nbuf is the number of bytes in buf.
+	nbuf int
+	// written is true if any additional data has been written.
+	written bool
+}
+
+var _ io.Writer = (*adWriter)(nil)
+
+// blockSize returns the rate of the underlying ASCON variant.
+func (w *adWriter) blockSize() int {
+	if w.iv == iv128a {
+		return BlockSize128a
+	}
+	return BlockSize128
+}
+
+func (w *adWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if n == 0 {
+		return 0, nil
+	}
+	w.written = true
+
+	bs := w.blockSize()
+	if w.nbuf > 0 {
+		c := copy(w.buf[w.nbuf:bs], p)
+		w.nbuf += c
+		p = p[c:]
+		if w.nbuf < bs {
+			return n, nil
+		}
+		w.blocks(w.buf[:bs])
+		w.nbuf = 0
+	}
+	if m := len(p) &^ (bs - 1); m > 0 {
+		w.blocks(p[:m])
+		p = p[m:]
+	}
+	w.nbuf = copy(w.buf[:], p)
+	return n, nil
+}
+
+// blocks absorbs full blocks of additional data.
+func (w *adWriter) blocks(p []byte) {
+	if w.iv == iv128a {
+		additionalData128a(w.s, p)
+	} else {
+		w.s.additionalDataBlocks128(p)
+	}
+}
+
+// finish pads and absorbs any remaining additional data, then
+// applies the domain separation constant.
+//
+// The adWriter must not be used after calling finish.
+func (w *adWriter) finish() {
+	if w.written {
+		if w.iv == iv128a {
+			w.s.finalAdditionalData128a(w.buf[:w.nbuf])
+		} else {
+			w.s.finalAdditionalData128(w.buf[:w.nbuf])
+		}
+	}
+	w.s.x4 ^= 1
+}
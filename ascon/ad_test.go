@@ -0,0 +1,89 @@
+package ascon
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// chunkedWriterTo writes its data in chunks of random sizes.
+type chunkedWriterTo struct {
+	data []byte
+	rng  *rand.Rand
+}
+
+func (c *chunkedWriterTo) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	p := c.data
+	for len(p) > 0 {
+		m := c.rng.Intn(len(p)) + 1
+		if _, err := w.Write(p[:m]); err != nil {
+			return n, err
+		}
+		n += int64(m)
+		p = p[m:]
+	}
+	return n, nil
+}
+
+func TestSealWriterTo(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(0xDEADBEEF))
+
+			key := make([]byte, KeySize)
+			nonce := make([]byte, NonceSize)
+			plaintext := make([]byte, 77)
+			ad := make([]byte, 4*BlockSize128a+3)
+			rng.Read(key)
+			rng.Read(nonce)
+			rng.Read(plaintext)
+			rng.Read(ad)
+
+			c, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			aead := c.(*AEAD)
+
+			for i := 0; i <= len(ad); i++ {
+				want := aead.Seal(nil, nonce, plaintext, ad[:i])
+
+				got, err := aead.SealWriterTo(nil, nonce, plaintext,
+					bytes.NewBuffer(ad[:i]))
+				if err != nil {
+					t.Fatalf("#%d: %v", i, err)
+				}
+				if !bytes.Equal(want, got) {
+					t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+				}
+
+				got, err = aead.SealWriterTo(nil, nonce, plaintext,
+					&chunkedWriterTo{data: ad[:i], rng: rng})
+				if err != nil {
+					t.Fatalf("#%d: %v", i, err)
+				}
+				if !bytes.Equal(want, got) {
+					t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+				}
+
+				pt, err := aead.OpenWriterTo(nil, nonce, want,
+					&chunkedWriterTo{data: ad[:i], rng: rng})
+				if err != nil {
+					t.Fatalf("#%d: %v", i, err)
+				}
+				if !bytes.Equal(pt, plaintext) {
+					t.Fatalf("#%d: expected %#x, got %#x", i, plaintext, pt)
+				}
+			}
+		})
+	}
+}
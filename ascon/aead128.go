@@ -0,0 +1,230 @@
+package ascon
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"runtime"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// ivAEAD128 is the Ascon-AEAD128 initialization vector from
+// NIST SP 800-232.
+const ivAEAD128 uint64 = 0x00001000808c0001
+
+// NewAEAD128 creates an instance of Ascon-AEAD128 as
+// standardized in NIST SP 800-232.
+//
+// Ascon-AEAD128 is derived from ASCON-128a, but is not
+// compatible with it: the standard loads the key, nonce, and
+// data into the state in little-endian order, uses a different
+// initialization vector, and pads and separates domains
+// accordingly. Use New128a to open ciphertexts created with the
+// v1.2 submission.
+//
+// The key and nonce sizes, tag size, and usage limits are the
+// same as New128a.
+func NewAEAD128(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("ascon: bad key length")
+	}
+	return &aead128{
+		k0: binary.LittleEndian.Uint64(key[0:8]),
+		k1: binary.LittleEndian.Uint64(key[8:16]),
+	}, nil
+}
+
+// aead128 is Ascon-AEAD128.
+type aead128 struct {
+	k0, k1 uint64
+}
+
+var _ cipher.AEAD = (*aead128)(nil)
+
+func (a *aead128) NonceSize() int {
+	return NonceSize
+}
+
+func (a *aead128) Overhead() int {
+	return TagSize
+}
+
+func (a *aead128) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	checkNonce(nonce)
+	checkPlaintextLen(len(plaintext))
+
+	ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+	if subtle.InexactOverlap(out, plaintext) {
+		panic("ascon: invalid buffer overlap")
+	}
+
+	var s state
+	a.init(&s, nonce)
+	s.additionalDataLE(additionalData)
+	s.encryptLE(out[:len(plaintext)], plaintext)
+	a.finalize(&s)
+	s.tagLE(out[len(plaintext):])
+	return ret
+}
+
+func (a *aead128) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	checkNonce(nonce)
+	if len(ciphertext) < TagSize {
+		return nil, errOpen
+	}
+	tag := ciphertext[len(ciphertext)-TagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-TagSize]
+
+	ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+	if subtle.InexactOverlap(out, ciphertext) {
+		panic("ascon: invalid buffer overlap")
+	}
+
+	var s state
+	a.init(&s, nonce)
+	s.additionalDataLE(additionalData)
+	s.decryptLE(out, ciphertext)
+	a.finalize(&s)
+
+	var expectedTag [TagSize]byte
+	s.tagLE(expectedTag[:])
+	if subtle.ConstantTimeCompare(expectedTag[:], tag) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		runtime.KeepAlive(out)
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+func (a *aead128) init(s *state, nonce []byte) {
+	s.x0 = ivAEAD128
+	s.x1 = a.k0
+	s.x2 = a.k1
+	s.x3 = binary.LittleEndian.Uint64(nonce[0:8])
+	s.x4 = binary.LittleEndian.Uint64(nonce[8:16])
+	p12(s)
+	s.x3 ^= a.k0
+	s.x4 ^= a.k1
+}
+
+func (a *aead128) finalize(s *state) {
+	s.x2 ^= a.k0
+	s.x3 ^= a.k1
+	p12(s)
+	s.x3 ^= a.k0
+	s.x4 ^= a.k1
+}
+
+// additionalDataLE absorbs the additional data and applies the
+// domain separation bit, which is the most significant bit of
+// x4 in the little-endian ordering.
+func (s *state) additionalDataLE(ad []byte) {
+	if len(ad) > 0 {
+		for len(ad) >= BlockSize128a {
+			s.x0 ^= binary.LittleEndian.Uint64(ad[0:8])
+			s.x1 ^= binary.LittleEndian.Uint64(ad[8:16])
+			p8(s)
+			ad = ad[BlockSize128a:]
+		}
+		if len(ad) >= 8 {
+			s.x0 ^= binary.LittleEndian.Uint64(ad[0:8])
+			s.x1 ^= le64n(ad[8:])
+			s.x1 ^= padLE(len(ad) - 8)
+		} else {
+			s.x0 ^= le64n(ad)
+			s.x0 ^= padLE(len(ad))
+		}
+		p8(s)
+	}
+	s.x4 ^= 1 << 63
+}
+
+func (s *state) encryptLE(dst, src []byte) {
+	for len(src) >= BlockSize128a {
+		s.x0 ^= binary.LittleEndian.Uint64(src[0:8])
+		s.x1 ^= binary.LittleEndian.Uint64(src[8:16])
+		binary.LittleEndian.PutUint64(dst[0:8], s.x0)
+		binary.LittleEndian.PutUint64(dst[8:16], s.x1)
+		p8(s)
+		src = src[BlockSize128a:]
+		dst = dst[BlockSize128a:]
+	}
+	if len(src) >= 8 {
+		s.x0 ^= binary.LittleEndian.Uint64(src[0:8])
+		s.x1 ^= le64n(src[8:])
+		binary.LittleEndian.PutUint64(dst[0:8], s.x0)
+		putLE64n(dst[8:], s.x1)
+		s.x1 ^= padLE(len(src) - 8)
+	} else {
+		s.x0 ^= le64n(src)
+		putLE64n(dst, s.x0)
+		s.x0 ^= padLE(len(src))
+	}
+}
+
+func (s *state) decryptLE(dst, src []byte) {
+	for len(src) >= BlockSize128a {
+		c0 := binary.LittleEndian.Uint64(src[0:8])
+		c1 := binary.LittleEndian.Uint64(src[8:16])
+		binary.LittleEndian.PutUint64(dst[0:8], s.x0^c0)
+		binary.LittleEndian.PutUint64(dst[8:16], s.x1^c1)
+		s.x0 = c0
+		s.x1 = c1
+		p8(s)
+		src = src[BlockSize128a:]
+		dst = dst[BlockSize128a:]
+	}
+	if len(src) >= 8 {
+		c0 := binary.LittleEndian.Uint64(src[0:8])
+		c1 := le64n(src[8:])
+		binary.LittleEndian.PutUint64(dst[0:8], s.x0^c0)
+		putLE64n(dst[8:], s.x1^c1)
+		s.x0 = c0
+		s.x1 = maskLE(s.x1, len(src)-8) | c1
+		s.x1 ^= padLE(len(src) - 8)
+	} else {
+		c0 := le64n(src)
+		putLE64n(dst, s.x0^c0)
+		s.x0 = maskLE(s.x0, len(src)) | c0
+		s.x0 ^= padLE(len(src))
+	}
+}
+
+func (s *state) tagLE(dst []byte) {
+	binary.LittleEndian.PutUint64(dst[0:8], s.x3)
+	binary.LittleEndian.PutUint64(dst[8:16], s.x4)
+}
+
+// padLE returns the padding for a little-endian word holding n
+// bytes of data.
+func padLE(n int) uint64 {
+	return 0x01 << (8 * n)
+}
+
+// le64n loads up to 8 bytes as a little-endian word.
+func le64n(b []byte) uint64 {
+	var x uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		x |= uint64(b[i]) << (8 * i)
+	}
+	return x
+}
+
+// putLE64n stores the low len(b) bytes of x in little-endian
+// order.
+func putLE64n(b []byte, x uint64) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(x >> (8 * i))
+	}
+}
+
+// maskLE clears the low n bytes of x.
+func maskLE(x uint64, n int) uint64 {
+	for i := 0; i < n; i++ {
+		x &^= 255 << (8 * i)
+	}
+	return x
+}
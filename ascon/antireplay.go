@@ -0,0 +1,184 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrReplay is returned by AntiReplay.Open when the sequence
+// number has already been accepted or is too old for the replay
+// window.
+var ErrReplay = errors.New("ascon: replayed or expired sequence number")
+
+// AntiReplay is an ASCON-128 AEAD for datagram protocols that
+// rejects replayed packets.
+//
+// Each packet carries a sequence number that is authenticated
+// along with the additional data. Open accepts packets that
+// arrive out of order as long as they are within the most
+// recent windowSize sequence numbers, and rejects duplicates and
+// anything older. This is the same scheme as IPsec (RFC 4303,
+// section 3.4.3) and DTLS.
+//
+// It is safe for concurrent use.
+type AntiReplay struct {
+	aead AEAD
+
+	mu     sync.Mutex
+	window replayWindow
+}
+
+// NewAntiReplay creates an AntiReplay with a replay window of
+// windowSize sequence numbers.
+func NewAntiReplay(key []byte, windowSize int) (*AntiReplay, error) {
+	if windowSize <= 0 {
+		return nil, errors.New("ascon: invalid window size")
+	}
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	return &AntiReplay{
+		aead:   *a,
+		window: newReplayWindow(uint64(windowSize)),
+	}, nil
+}
+
+// Overhead returns the maximum difference between the lengths
+// of a plaintext and its ciphertext.
+func (r *AntiReplay) Overhead() int {
+	return r.aead.Overhead()
+}
+
+// Seal encrypts and authenticates plaintext, authenticates the
+// sequence number and additional data, and appends the result
+// to dst, returning the updated slice.
+//
+// Sequence numbers should increase monotonically. The sequence
+// number is not part of the output and must be sent alongside
+// the ciphertext.
+func (r *AntiReplay) Seal(dst []byte, seq uint64, nonce, plaintext, additionalData []byte) []byte {
+	ad := seqAD(seq, additionalData)
+	out, _ := r.aead.SealWriterTo(dst, nonce, plaintext, &ad)
+	return out
+}
+
+// Open decrypts and authenticates ciphertext, authenticates the
+// sequence number and additional data, and, if successful,
+// appends the resulting plaintext to dst, returning the updated
+// slice.
+//
+// Open returns ErrReplay without decrypting anything if seq has
+// already been accepted or is too old. Only packets that
+// authenticate successfully update the replay window.
+func (r *AntiReplay) Open(dst []byte, seq uint64, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	r.mu.Lock()
+	ok := r.window.check(seq)
+	r.mu.Unlock()
+	if !ok {
+		return nil, ErrReplay
+	}
+
+	ad := seqAD(seq, additionalData)
+	ret, err := r.aead.OpenWriterTo(dst, nonce, ciphertext, &ad)
+	if err != nil {
+		return nil, err
+	}
+
+	// Another goroutine might have accepted the same packet
+	// while this one was decrypting it.
+	r.mu.Lock()
+	ok = r.window.check(seq)
+	if ok {
+		r.window.update(seq)
+	}
+	r.mu.Unlock()
+	if !ok {
+		out := ret[len(dst):]
+		for i := range out {
+			out[i] = 0
+		}
+		runtime.KeepAlive(out)
+		return nil, ErrReplay
+	}
+	return ret, nil
+}
+
+// seqAD returns the additional data that binds seq to ad.
+//
+// The sequence number has a fixed size, so the encoding is
+// unambiguous.
+func seqAD(seq uint64, ad []byte) adChunks {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], seq)
+	return adChunks{b[:], ad}
+}
+
+// replayWindow is a sliding window of accepted sequence numbers.
+//
+// It is a ring of bits, as in RFC 6479, so advancing the window
+// only clears the words that are reused.
+type replayWindow struct {
+	// size is the number of sequence numbers in the window.
+	size uint64
+	// bits records the accepted sequence numbers. It has at
+	// least one more word than the window needs, so the
+	// window never overlaps the word being cleared.
+	bits []uint64
+	// top is the largest accepted sequence number.
+	top uint64
+	// any is set once a sequence number has been accepted.
+	any bool
+}
+
+func newReplayWindow(size uint64) replayWindow {
+	return replayWindow{
+		size: size,
+		bits: make([]uint64, (size+63)/64+1),
+	}
+}
+
+// check reports whether seq is new and within the window.
+func (w *replayWindow) check(seq uint64) bool {
+	if !w.any || seq > w.top {
+		return true
+	}
+	if w.top-seq >= w.size {
+		return false
+	}
+	i, b := w.index(seq)
+	return w.bits[i]&b == 0
+}
+
+// update records seq as accepted, advancing the window if
+// needed.
+//
+// seq must have been checked with check.
+func (w *replayWindow) update(seq uint64) {
+	if w.any && seq/64 > w.top/64 {
+		n := uint64(len(w.bits))
+		from, to := w.top/64+1, seq/64
+		if to-from >= n {
+			for i := range w.bits {
+				w.bits[i] = 0
+			}
+		} else {
+			for j := from; j <= to; j++ {
+				w.bits[j%n] = 0
+			}
+		}
+	}
+	if !w.any || seq > w.top {
+		w.top = seq
+		w.any = true
+	}
+	i, b := w.index(seq)
+	w.bits[i] |= b
+}
+
+// index returns the word index and bit for seq.
+func (w *replayWindow) index(seq uint64) (int, uint64) {
+	return int((seq / 64) % uint64(len(w.bits))), 1 << (seq % 64)
+}
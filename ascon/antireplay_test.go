@@ -0,0 +1,113 @@
+package ascon
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestAntiReplay(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 1
+	r, err := NewAntiReplay(key, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("hello, world")
+	nonce := func(seq uint64) []byte {
+		n := make([]byte, NonceSize)
+		n[0] = byte(seq)
+		n[1] = byte(seq >> 8)
+		return n
+	}
+	seal := func(seq uint64) []byte {
+		return r.Seal(nil, seq, nonce(seq), plaintext, nil)
+	}
+	open := func(seq uint64, ciphertext []byte) error {
+		got, err := r.Open(nil, seq, nonce(seq), ciphertext, nil)
+		if err == nil && !bytes.Equal(got, plaintext) {
+			t.Fatalf("%d: expected %q, got %q", seq, plaintext, got)
+		}
+		return err
+	}
+
+	for _, tc := range []struct {
+		seq uint64
+		err error
+	}{
+		{100, nil},
+		{100, ErrReplay},
+		{99, nil},       // reordered
+		{37, nil},       // oldest in the window
+		{36, ErrReplay}, // too old
+		{99, ErrReplay},
+		{200, nil},
+		{150, nil},
+		{136, ErrReplay},
+		{137, nil},
+		{201, nil},
+	} {
+		if err := open(tc.seq, seal(tc.seq)); err != tc.err {
+			t.Fatalf("%d: expected %v, got %v", tc.seq, tc.err, err)
+		}
+	}
+
+	// The sequence number is authenticated.
+	ciphertext := seal(300)
+	if err := open(301, ciphertext); err == nil || err == ErrReplay {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+
+	// Forgeries do not update the window.
+	ciphertext[0] ^= 1
+	if err := open(300, ciphertext); err == nil || err == ErrReplay {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+	ciphertext[0] ^= 1
+	if err := open(300, ciphertext); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewAntiReplay(key, 0); err == nil {
+		t.Fatal("expected an error for an empty window")
+	}
+}
+
+// TestReplayWindow tests replayWindow against a simple model.
+func TestReplayWindow(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for _, size := range []uint64{1, 2, 63, 64, 65, 128, 200} {
+		w := newReplayWindow(size)
+		seen := make(map[uint64]bool)
+		var top uint64
+		for i := 0; i < 20000; i++ {
+			// Mostly small steps around the top of the window,
+			// with the occasional large jump.
+			var seq uint64
+			switch rng.Intn(10) {
+			case 0:
+				seq = top + uint64(rng.Intn(1000))
+			default:
+				seq = top + uint64(rng.Intn(8))
+				seq -= uint64(rng.Intn(int(2*size) + 8))
+				if seq > top+8 { // wrapped
+					seq = 0
+				}
+			}
+
+			want := len(seen) == 0 || seq > top ||
+				(top-seq < size && !seen[seq])
+			if got := w.check(seq); got != want {
+				t.Fatalf("%d: #%d: check(%d) with top %d: expected %t, got %t",
+					size, i, seq, top, want, got)
+			}
+			if want {
+				w.update(seq)
+				seen[seq] = true
+				if seq > top {
+					top = seq
+				}
+			}
+		}
+	}
+}
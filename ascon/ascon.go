@@ -1,8 +1,15 @@
-// Package ascon implements the ASCON AEAD cipher.
+// Package ascon implements the ASCON AEAD and hash functions.
+//
+// New128, New128a, New80pq, and NewHash implement ASCON v1.2,
+// the version selected by the NIST Lightweight Cryptography
+// competition. NewAEAD128, NewHash256, NewXOF128, and NewCXOF
+// implement the variants standardized in NIST SP 800-232, which
+// are not compatible with v1.2.
 //
 // References:
 //
 //    [ascon]: https://ascon.iaik.tugraz.at
+//    [SP 800-232]: https://doi.org/10.6028/NIST.SP.800-232
 //
 package ascon
 
@@ -10,15 +17,21 @@ import (
 	"crypto/cipher"
 	"encoding/binary"
 	"errors"
+	"math"
 	"runtime"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/ericlagergren/subtle"
 )
 
 //go:generate go run github.com/ericlagergren/lwcrypto/ascon/internal/cmd/pgen
 
-var errOpen = errors.New("ascon: message authentication failed")
+var (
+	errOpen    = errors.New("ascon: message authentication failed")
+	errZeroKey = errors.New("ascon: key is all zeros")
+	errLength  = errors.New("ascon: unexpected plaintext length")
+)
 
 const (
 	// BlockSize128a is the size in bytes of an ASCON-128a block.
@@ -28,6 +41,8 @@ const (
 	// KeySize is the size in bytes of ASCON-128 and ASCON-128a
 	// keys.
 	KeySize = 16
+	// KeySize80pq is the size in bytes of ASCON-80pq keys.
+	KeySize80pq = 20
 	// NonceSize is the size in bytes of ASCON-128 and ASCON-128a
 	// nonces.
 	NonceSize = 16
@@ -36,12 +51,33 @@ const (
 	TagSize = 16
 )
 
-type ascon struct {
+// AEAD is an ASCON-128 or ASCON-128a AEAD.
+//
+// The cipher.AEAD returned by New128 and New128a is an *AEAD.
+// Use a type assertion to access its additional methods.
+type AEAD struct {
+	// adBytes and ptBytes count the bytes of additional data
+	// and plaintext processed so far. They are accessed
+	// atomically and must be 64-bit aligned, so they come first.
+	adBytes uint64
+	ptBytes uint64
+
 	k0, k1 uint64
-	iv     uint64
+	// k2 is the last 64 bits of an ASCON-80pq key. For
+	// ASCON-80pq, k0 holds the first 32 bits of the key.
+	k2 uint64
+	iv uint64
+	// domain is the domain separator set by NewWithDomain.
+	domain uint64
+	// lengthBlock is set by NewWithLengthBlock.
+	lengthBlock bool
+	// stats is set by EnableStats.
+	stats bool
+	// destroyed is set by Destroy.
+	destroyed bool
 }
 
-var _ cipher.AEAD = (*ascon)(nil)
+var _ cipher.AEAD = (*AEAD)(nil)
 
 // New128 creates a 128-bit ASCON-128 AEAD.
 //
@@ -58,14 +94,11 @@ var _ cipher.AEAD = (*ascon)(nil)
 //
 // Refer to ASCON's documentation for more information.
 func New128(key []byte) (cipher.AEAD, error) {
-	if len(key) != KeySize {
-		return nil, errors.New("ascon: bad key length")
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
 	}
-	return &ascon{
-		k0: binary.BigEndian.Uint64(key[0:8]),
-		k1: binary.BigEndian.Uint64(key[8:16]),
-		iv: iv128,
-	}, nil
+	return a, nil
 }
 
 // New128a creates a 128-bit ASCON-128a AEAD.
@@ -83,86 +116,399 @@ func New128(key []byte) (cipher.AEAD, error) {
 //
 // Refer to ASCON's documentation for more information.
 func New128a(key []byte) (cipher.AEAD, error) {
+	a, err := newAEAD(key, iv128a)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// New80pq creates an ASCON-80pq AEAD with a 160-bit key.
+//
+// ASCON-80pq is ASCON-128 with a larger key, which provides
+// additional protection against key search by quantum
+// computers. The nonce size, tag size, and usage limits are the
+// same as New128.
+func New80pq(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize80pq {
+		return nil, errors.New("ascon: bad key length")
+	}
+	return &AEAD{
+		k0: uint64(binary.BigEndian.Uint32(key[0:4])),
+		k1: binary.BigEndian.Uint64(key[4:12]),
+		k2: binary.BigEndian.Uint64(key[12:20]),
+		iv: iv80pq,
+	}, nil
+}
+
+// SecurityLevel selects between ASCON-128 and ASCON-128a.
+type SecurityLevel int
+
+const (
+	// Robust selects ASCON-128.
+	Robust SecurityLevel = iota + 1
+	// Fast selects ASCON-128a.
+	Fast
+)
+
+func (l SecurityLevel) String() string {
+	switch l {
+	case Robust:
+		return "Robust"
+	case Fast:
+		return "Fast"
+	default:
+		return "SecurityLevel(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// New creates a 128-bit AEAD for the security level.
+//
+// Robust creates an ASCON-128 AEAD (see New128) and Fast creates
+// an ASCON-128a AEAD (see New128a).
+func New(level SecurityLevel, key []byte) (cipher.AEAD, error) {
+	switch level {
+	case Robust:
+		return New128(key)
+	case Fast:
+		return New128a(key)
+	default:
+		return nil, errors.New("ascon: invalid security level: " + level.String())
+	}
+}
+
+// Implementation reports which implementation of the
+// ASCON permutation is compiled in: "amd64", "arm64", or
+// "generic".
+//
+// It is intended for diagnostics, such as logging at startup.
+func Implementation() string {
+	return implementation
+}
+
+// NewStrict is like New128, but returns an error if the key is
+// all zeros.
+//
+// An all-zero key is not weak as far as ASCON is concerned, but
+// it usually means that the key was never initialized. Use
+// NewStrict to catch such bugs early.
+func NewStrict(key []byte) (cipher.AEAD, error) {
+	var zero [KeySize]byte
+	if subtle.ConstantTimeCompare(key, zero[:]) == 1 {
+		return nil, errZeroKey
+	}
+	return New128(key)
+}
+
+// NewWithDomain creates a 128-bit ASCON-128 AEAD that is bound
+// to domain.
+//
+// The domain is XORed into the capacity part of the state
+// immediately after initialization, so ciphertexts sealed under
+// different domains do not open under each other even if they
+// use the same key and nonce. Unlike prefixing the additional
+// data, this adds no overhead.
+//
+// This is not part of the ASCON specification. Both sides must
+// agree on the domain. A domain of zero is equivalent to
+// New128.
+func NewWithDomain(key []byte, domain uint64) (cipher.AEAD, error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	a.domain = domain
+	return a, nil
+}
+
+// NewWithLengthBlock creates a 128-bit ASCON-128 AEAD that
+// authenticates the lengths of the additional data and
+// plaintext explicitly, like the length block in GCM.
+//
+// Before finalization, it absorbs the 16-byte block
+//
+//	BE64(len(additionalData)) || BE64(len(plaintext))
+//
+// as two ASCON-128 blocks, where each length is in bytes.
+//
+// This is not part of the ASCON specification and its
+// ciphertexts are not compatible with New128. ASCON's padding
+// already makes the tag depend on both lengths, so the length
+// block adds no security. It exists only to interoperate with
+// systems that require it.
+func NewWithLengthBlock(key []byte) (cipher.AEAD, error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	a.lengthBlock = true
+	return a, nil
+}
+
+// NewVariantBound creates a 128-bit AEAD for the security level
+// that also binds the variant's identity into the state.
+//
+// Standard ASCON-128 and ASCON-128a already use different
+// initialization vectors, so a ciphertext sealed by one does
+// not open under the other. NewVariantBound additionally XORs
+// a per-variant domain constant into the state after
+// initialization (see NewWithDomain), which keeps the variants
+// apart even in deployments that mix in other domain
+// separation, and keeps bound ciphertexts apart from those of
+// New128 and New128a.
+//
+// This is not part of the ASCON specification and its
+// ciphertexts are not compatible with New128 or New128a. Both
+// sides must agree to use it.
+func NewVariantBound(key []byte, level SecurityLevel) (cipher.AEAD, error) {
+	var iv, domain uint64
+	switch level {
+	case Robust:
+		iv, domain = iv128, domainRobust
+	case Fast:
+		iv, domain = iv128a, domainFast
+	default:
+		return nil, errors.New("ascon: invalid security level: " + level.String())
+	}
+	a, err := newAEAD(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	a.domain = domain
+	return a, nil
+}
+
+// Domain constants used by NewVariantBound.
+const (
+	domainRobust uint64 = 0x4153434f4e313238 // "ASCON128"
+	domainFast   uint64 = 0x4153434f4e313261 // "ASCON12a"
+)
+
+// newAEAD creates an AEAD for the variant identified by iv.
+func newAEAD(key []byte, iv uint64) (*AEAD, error) {
 	if len(key) != KeySize {
 		return nil, errors.New("ascon: bad key length")
 	}
-	return &ascon{
+	return &AEAD{
 		k0: binary.BigEndian.Uint64(key[0:8]),
 		k1: binary.BigEndian.Uint64(key[8:16]),
-		iv: iv128a,
+		iv: iv,
 	}, nil
 }
 
-func (a *ascon) NonceSize() int {
+func (a *AEAD) NonceSize() int {
 	return NonceSize
 }
 
-func (a *ascon) Overhead() int {
+func (a *AEAD) Overhead() int {
 	return TagSize
 }
 
-func (a *ascon) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
-	if len(nonce) != NonceSize {
-		panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+func (a *AEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	checkNonce(nonce)
+	var s state
+	a.init(&s, nonce)
+	a.additionalData(&s, additionalData)
+	if a.count(uint64(len(additionalData)), uint64(len(plaintext))) {
+		panic("ascon: key usage limit exceeded")
 	}
-	// TODO(eric): ciphertext max length?
+	return a.seal(&s, dst, plaintext, uint64(len(additionalData)))
+}
 
-	n0 := binary.BigEndian.Uint64(nonce[0:8])
-	n1 := binary.BigEndian.Uint64(nonce[8:16])
+func (a *AEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	checkNonce(nonce)
+	if len(ciphertext) < TagSize {
+		return nil, errOpen
+	}
+	// TODO(eric): ciphertext max length?
 
 	var s state
-	s.init(a.iv, a.k0, a.k1, n0, n1)
+	a.init(&s, nonce)
+	a.additionalData(&s, additionalData)
+	a.count(uint64(len(additionalData)), uint64(len(ciphertext)-TagSize))
+	return a.open(&s, dst, ciphertext, uint64(len(additionalData)))
+}
 
-	if a.iv == iv128a {
-		s.additionalData128a(additionalData)
-	} else {
-		s.additionalData128(additionalData)
+// OpenExact is like Open, but returns an error without
+// decrypting anything if the plaintext would not be exactly
+// expectedLen bytes long.
+//
+// It is useful for protocols that know the length of the
+// plaintext in advance, since it catches framing errors early.
+func (a *AEAD) OpenExact(dst, nonce, ciphertext, additionalData []byte, expectedLen int) ([]byte, error) {
+	checkNonce(nonce)
+	if len(ciphertext)-TagSize != expectedLen || expectedLen < 0 {
+		return nil, errLength
 	}
+	return a.Open(dst, nonce, ciphertext, additionalData)
+}
 
-	ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
-	if subtle.InexactOverlap(out, plaintext) {
-		panic("ascon: invalid buffer overlap")
-	}
-	if a.iv == iv128a {
-		s.encrypt128a(out[:len(plaintext)], plaintext)
-	} else {
-		s.encrypt128(out[:len(plaintext)], plaintext)
-	}
+// Stats records the amount of data processed by an AEAD.
+//
+// Open counts its input whether or not the ciphertext is
+// authentic, since a forgery attempt still uses the key.
+type Stats struct {
+	// AdditionalData is the number of bytes of additional data
+	// processed by Seal and Open.
+	AdditionalData uint64
+	// Plaintext is the number of bytes of plaintext sealed by
+	// Seal or opened by Open, whether or not the ciphertext
+	// was authentic.
+	Plaintext uint64
+}
 
-	if a.iv == iv128a {
-		s.finalize128a(a.k0, a.k1)
-	} else {
-		s.finalize128(a.k0, a.k1)
+// EnableStats turns on the counters returned by Stats.
+//
+// Counting costs two atomic additions per call to Seal or Open,
+// which contend when one key is used from many goroutines, so
+// it is off by default. EnableStats must be called before the
+// AEAD is used concurrently.
+func (a *AEAD) EnableStats() {
+	a.stats = true
+}
+
+// Stats returns the cumulative amount of data processed by the
+// AEAD since EnableStats was called.
+//
+// It can be used to monitor how close a key is to its usage
+// limit. It is safe to call Stats concurrently with other
+// methods. Without EnableStats, Stats returns zero.
+func (a *AEAD) Stats() Stats {
+	return Stats{
+		AdditionalData: atomic.LoadUint64(&a.adBytes),
+		Plaintext:      atomic.LoadUint64(&a.ptBytes),
 	}
-	s.tag(out[len(out)-TagSize:])
+}
 
-	return ret
+// ResetCounters zeroes the counts returned by Stats without
+// changing the key.
+//
+// Only use ResetCounters when the application has logically
+// rekeyed, such as at the start of a new epoch, and guarantees
+// that nonces used before the reset are never used again.
+// Otherwise, resetting the counters only hides how much the key
+// has been used.
+func (a *AEAD) ResetCounters() {
+	atomic.StoreUint64(&a.adBytes, 0)
+	atomic.StoreUint64(&a.ptBytes, 0)
 }
 
-func (a *ascon) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
-	if len(nonce) != NonceSize {
-		panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
-	}
-	if len(ciphertext) < TagSize {
-		return nil, errOpen
+// maxKeyUsage is the maximum number of bytes of additional data
+// and plaintext that can be processed with one key before Seal
+// refuses to seal more.
+//
+// The actual limit is 2^68 bytes, which cannot be counted with
+// a uint64, so the limit is only enforced in tests that lower
+// it.
+var maxKeyUsage uint64 = math.MaxUint64
+
+// count adds to the cumulative amount of data processed and
+// reports whether the key has now been used for more than
+// maxKeyUsage bytes.
+//
+// Without EnableStats or a lowered maxKeyUsage it does nothing,
+// since the limit cannot be reached.
+func (a *AEAD) count(ad, pt uint64) bool {
+	if !a.stats && maxKeyUsage == math.MaxUint64 {
+		return false
 	}
-	// TODO(eric): ciphertext max length?
+	ad = atomic.AddUint64(&a.adBytes, ad)
+	pt = atomic.AddUint64(&a.ptBytes, pt)
+	return ad > maxKeyUsage || pt > maxKeyUsage-ad
+}
 
-	tag := ciphertext[len(ciphertext)-TagSize:]
-	ciphertext = ciphertext[:len(ciphertext)-TagSize]
+// Destroy zeroes the key.
+//
+// The AEAD must not be used after calling Destroy. Doing so
+// causes a panic.
+func (a *AEAD) Destroy() {
+	a.k0 = 0
+	a.k1 = 0
+	a.k2 = 0
+	a.destroyed = true
+	runtime.KeepAlive(a)
+}
 
+// init initializes s with the key and nonce.
+func (a *AEAD) init(s *state, nonce []byte) {
+	if a.destroyed {
+		panic("ascon: use after Destroy")
+	}
 	n0 := binary.BigEndian.Uint64(nonce[0:8])
 	n1 := binary.BigEndian.Uint64(nonce[8:16])
+	if a.iv == iv80pq {
+		s.init80pq(a.k0, a.k1, a.k2, n0, n1)
+	} else {
+		s.init(a.iv, a.k0, a.k1, n0, n1)
+	}
+	s.x2 ^= a.domain
+}
 
-	var s state
-	s.init(a.iv, a.k0, a.k1, n0, n1)
+// additionalData absorbs the entirety of the additional data.
+func (a *AEAD) additionalData(s *state, ad []byte) {
+	if a.iv == iv128a {
+		s.additionalData128a(ad)
+	} else {
+		s.additionalData128(ad)
+	}
+}
 
+// seal encrypts and authenticates plaintext after adLen bytes
+// of additional data have been absorbed into s.
+//
+// The caller must have already counted the data and checked
+// the key usage limit.
+func (a *AEAD) seal(s *state, dst, plaintext []byte, adLen uint64) []byte {
+	checkPlaintextLen(len(plaintext))
+	ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+	if subtle.InexactOverlap(out, plaintext) {
+		panic("ascon: invalid buffer overlap")
+	}
+	a.sealDetached(s, out[:len(plaintext)], out[len(plaintext):], plaintext, adLen)
+	return ret
+}
+
+// sealDetached encrypts plaintext into dst and writes the tag
+// to tag after adLen bytes of additional data have been
+// absorbed into s.
+//
+// dst must be len(plaintext) bytes long and must not inexactly
+// overlap plaintext.
+func (a *AEAD) sealDetached(s *state, dst, tag, plaintext []byte, adLen uint64) {
 	if a.iv == iv128a {
-		s.additionalData128a(additionalData)
+		s.encrypt128a(dst, plaintext)
 	} else {
-		s.additionalData128(additionalData)
+		s.encrypt128(dst, plaintext)
 	}
+	a.finalize(s, adLen, uint64(len(plaintext)))
+	s.tag(tag)
+}
+
+// open decrypts and authenticates ciphertext after adLen bytes
+// of additional data have been absorbed into s.
+//
+// The ciphertext must be at least TagSize bytes long.
+func (a *AEAD) open(s *state, dst, ciphertext []byte, adLen uint64) ([]byte, error) {
+	tag := ciphertext[len(ciphertext)-TagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-TagSize]
+	return a.openDetached(s, dst, ciphertext, tag, adLen)
+}
 
+// openDetached decrypts ciphertext and checks it against tag
+// after adLen bytes of additional data have been absorbed into
+// s.
+//
+// The plaintext is written directly to dst and zeroed if the
+// tag does not match. Decrypting into a scratch buffer and
+// copying it to dst only after the tag check was measured to be
+// no faster: the sponge is inherently sequential, so there is
+// nothing to overlap, and the scratch buffer would have to be
+// allocated.
+//
+// Only the first len(tag) bytes of the computed tag are
+// compared, so callers must check that tag is TagSize bytes or,
+// for New128WithTagSize, the configured size.
+func (a *AEAD) openDetached(s *state, dst, ciphertext, tag []byte, adLen uint64) ([]byte, error) {
 	ret, out := subtle.SliceForAppend(dst, len(ciphertext))
 	if subtle.InexactOverlap(out, ciphertext) {
 		panic("ascon: invalid buffer overlap")
@@ -172,17 +518,12 @@ func (a *ascon) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, err
 	} else {
 		s.decrypt128(out, ciphertext)
 	}
+	a.finalize(s, adLen, uint64(len(ciphertext)))
 
-	if a.iv == iv128a {
-		s.finalize128a(a.k0, a.k1)
-	} else {
-		s.finalize128(a.k0, a.k1)
-	}
-
-	expectedTag := make([]byte, TagSize)
-	s.tag(expectedTag)
+	var expectedTag [TagSize]byte
+	s.tag(expectedTag[:])
 
-	if subtle.ConstantTimeCompare(expectedTag, tag) != 1 {
+	if subtle.ConstantTimeCompare(expectedTag[:len(tag)], tag) != 1 {
 		for i := range out {
 			out[i] = 0
 		}
@@ -192,15 +533,81 @@ func (a *ascon) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, err
 	return ret, nil
 }
 
+// NonceSizeError describes a nonce with the wrong length.
+type NonceSizeError struct {
+	// Got is the length of the nonce.
+	Got int
+	// Want is the required length.
+	Want int
+}
+
+func (e *NonceSizeError) Error() string {
+	return "ascon: incorrect nonce length: " + strconv.Itoa(e.Got)
+}
+
+// validateNonce returns a *NonceSizeError if nonce is not want
+// bytes long.
+func validateNonce(nonce []byte, want int) error {
+	if len(nonce) != want {
+		return &NonceSizeError{Got: len(nonce), Want: want}
+	}
+	return nil
+}
+
+// checkNonce panics if nonce is not NonceSize bytes long.
+func checkNonce(nonce []byte) {
+	if err := validateNonce(nonce, NonceSize); err != nil {
+		panic(err.Error())
+	}
+}
+
+// maxInt is the largest value of an int.
+const maxInt = int(^uint(0) >> 1)
+
+// checkPlaintextLen panics if the ciphertext for n bytes of
+// plaintext would be too large for an int, which is only
+// possible on 32-bit platforms.
+func checkPlaintextLen(n int) {
+	if n > maxInt-TagSize {
+		panic("ascon: plaintext too large")
+	}
+}
+
+func (a *AEAD) finalize(s *state, adLen, ptLen uint64) {
+	if a.lengthBlock {
+		s.x0 ^= adLen
+		p6(s)
+		s.x0 ^= ptLen
+		p6(s)
+	}
+	switch a.iv {
+	case iv128a:
+		s.finalize128a(a.k0, a.k1)
+	case iv80pq:
+		s.finalize80pq(a.k0, a.k1, a.k2)
+	default:
+		s.finalize128(a.k0, a.k1)
+	}
+}
+
 const (
 	iv128  uint64 = 0x80400c0600000000 // Ascon-128
 	iv128a uint64 = 0x80800c0800000000 // Ascon-128a
+	iv80pq uint64 = 0xa0400c0600000000 // Ascon-80pq
 )
 
 type state struct {
 	x0, x1, x2, x3, x4 uint64
 }
 
+// init loads the IV, key, and nonce and runs the
+// initialization permutation.
+//
+// There is no useful key-only precomputation: the key and nonce
+// are loaded into the state together and the very first
+// permutation mixes them, so every word of the state after p12
+// depends on the nonce. Caching anything per key would save at
+// most a couple of word loads.
 func (s *state) init(iv, k0, k1, n0, n1 uint64) {
 	s.x0 = iv
 	s.x1 = k0
@@ -212,6 +619,31 @@ func (s *state) init(iv, k0, k1, n0, n1 uint64) {
 	s.x4 ^= k1
 }
 
+// init80pq is like init, but for ASCON-80pq's 160-bit key
+// k0 || k1 || k2, where k0 is 32 bits.
+func (s *state) init80pq(k0, k1, k2, n0, n1 uint64) {
+	s.x0 = iv80pq | k0
+	s.x1 = k1
+	s.x2 = k2
+	s.x3 = n0
+	s.x4 = n1
+	p12(s)
+	s.x2 ^= k0
+	s.x3 ^= k1
+	s.x4 ^= k2
+}
+
+// finalize80pq is like finalize128, but for ASCON-80pq's
+// 160-bit key k0 || k1 || k2, where k0 is 32 bits.
+func (s *state) finalize80pq(k0, k1, k2 uint64) {
+	s.x1 ^= k0<<32 | k1>>32
+	s.x2 ^= k1<<32 | k2>>32
+	s.x3 ^= k2 << 32
+	p12(s)
+	s.x3 ^= k1
+	s.x4 ^= k2
+}
+
 func (s *state) finalize128a(k0, k1 uint64) {
 	s.x2 ^= k0
 	s.x3 ^= k1
@@ -227,19 +659,25 @@ func (s *state) additionalData128a(ad []byte) {
 			additionalData128a(s, ad[:n])
 			ad = ad[n:]
 		}
-		if len(ad) >= 8 {
-			s.x0 ^= binary.BigEndian.Uint64(ad[0:8])
-			s.x1 ^= be64n(ad[8:])
-			s.x1 ^= pad(len(ad) - 8)
-		} else {
-			s.x0 ^= be64n(ad)
-			s.x0 ^= pad(len(ad))
-		}
-		p8(s)
+		s.finalAdditionalData128a(ad)
 	}
 	s.x4 ^= 1
 }
 
+// finalAdditionalData128a absorbs the final, partial block of
+// additional data.
+func (s *state) finalAdditionalData128a(ad []byte) {
+	if len(ad) >= 8 {
+		s.x0 ^= binary.BigEndian.Uint64(ad[0:8])
+		s.x1 ^= be64n(ad[8:])
+		s.x1 ^= pad(len(ad) - 8)
+	} else {
+		s.x0 ^= be64n(ad)
+		s.x0 ^= pad(len(ad))
+	}
+	p8(s)
+}
+
 func (s *state) encrypt128a(dst, src []byte) {
 	n := len(src) &^ (BlockSize128a - 1)
 	if n > 0 {
@@ -295,19 +733,47 @@ func (s *state) finalize128(k0, k1 uint64) {
 
 func (s *state) additionalData128(ad []byte) {
 	if len(ad) > 0 {
-		for len(ad) >= BlockSize128 {
-			s.x0 ^= binary.BigEndian.Uint64(ad[0:8])
-			p6(s)
-			ad = ad[BlockSize128:]
-		}
-		s.x0 ^= be64n(ad)
-		s.x0 ^= pad(len(ad))
-		p6(s)
+		ad = s.additionalDataBlocks128(ad)
+		s.finalAdditionalData128(ad)
 	}
 	s.x4 ^= 1
 }
 
+// additionalDataBlocks128 absorbs each full block of additional
+// data and returns the remaining partial block.
+func (s *state) additionalDataBlocks128(ad []byte) []byte {
+	for len(ad) >= BlockSize128 {
+		s.x0 ^= binary.BigEndian.Uint64(ad[0:8])
+		p6(s)
+		ad = ad[BlockSize128:]
+	}
+	return ad
+}
+
+// finalAdditionalData128 absorbs the final, partial block of
+// additional data.
+func (s *state) finalAdditionalData128(ad []byte) {
+	s.x0 ^= be64n(ad)
+	s.x0 ^= pad(len(ad))
+	p6(s)
+}
+
 func (s *state) encrypt128(dst, src []byte) {
+	n := len(src) &^ (BlockSize128 - 1)
+	if n > 0 {
+		s.encryptBlocks128(dst[:n], src[:n])
+		src = src[n:]
+		dst = dst[n:]
+	}
+	s.x0 ^= be64n(src)
+	put64n(dst, s.x0)
+	s.x0 ^= pad(len(src))
+}
+
+// encryptBlocks128 encrypts full blocks without padding.
+//
+// len(src) must be a multiple of BlockSize128.
+func (s *state) encryptBlocks128(dst, src []byte) {
 	for len(src) >= BlockSize128 && len(dst) >= BlockSize128 {
 		s.x0 ^= binary.BigEndian.Uint64(src[0:8])
 		binary.BigEndian.PutUint64(dst[0:8], s.x0)
@@ -315,9 +781,6 @@ func (s *state) encrypt128(dst, src []byte) {
 		src = src[BlockSize128:]
 		dst = dst[BlockSize128:]
 	}
-	s.x0 ^= be64n(src)
-	put64n(dst, s.x0)
-	s.x0 ^= pad(len(src))
 }
 
 func (s *state) decrypt128(dst, src []byte) {
@@ -0,0 +1,6 @@
+//go:build gc && !purego && !faultinject
+// +build gc,!purego,!faultinject
+
+package ascon
+
+const implementation = "amd64"
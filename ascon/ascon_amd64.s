@@ -1,6 +1,6 @@
 // Code generated by command: go run asm.go -out out/ascon_amd64.s -stubs out/stub_amd64.go -pkg ascon. DO NOT EDIT.
 
-// +build gc,!purego
+// +build gc,!purego,!faultinject
 
 #include "textflag.h"
 
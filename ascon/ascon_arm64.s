@@ -1,5 +1,5 @@
-//go:build gc && !purego
-// +build gc,!purego
+//go:build gc && !purego && !faultinject
+// +build gc,!purego,!faultinject
 
 #include "textflag.h"
 
@@ -0,0 +1,101 @@
+//go:build faultinject
+// +build faultinject
+
+package ascon
+
+import (
+	"strconv"
+	"sync"
+)
+
+// The faultinject build uses the generic permutation, running
+// each round separately so that InjectFault can perturb it.
+// Other builds are unaffected.
+
+const implementation = "generic"
+
+// fault is the fault set by InjectFault.
+var fault struct {
+	mu    sync.Mutex
+	armed bool
+	round int
+	bit   int
+}
+
+// InjectFault arranges for a single-bit fault in the next
+// permutation that executes round.
+//
+// Rounds are numbered from 0 through 11 as in the 12-round
+// permutation, so the 6-round permutation executes rounds 6
+// through 11. After the round, bit is flipped in the state,
+// where bits 0 through 63 are x0, 64 through 127 are x1, and so
+// on. The fault fires once.
+//
+// Faults do not affect the ASCON-128a block functions, which
+// inline their rounds.
+//
+// InjectFault is only available with the faultinject build tag.
+func InjectFault(round, bit int) {
+	if round < 0 || round > 11 {
+		panic("ascon: invalid round: " + strconv.Itoa(round))
+	}
+	if bit < 0 || bit >= 5*64 {
+		panic("ascon: invalid bit: " + strconv.Itoa(bit))
+	}
+	fault.mu.Lock()
+	fault.armed = true
+	fault.round = round
+	fault.bit = bit
+	fault.mu.Unlock()
+}
+
+// maybeFault applies the pending fault, if any, after round r.
+func maybeFault(s *state, r int) {
+	fault.mu.Lock()
+	defer fault.mu.Unlock()
+
+	if !fault.armed || fault.round != r {
+		return
+	}
+	fault.armed = false
+	x := [...]*uint64{&s.x0, &s.x1, &s.x2, &s.x3, &s.x4}
+	*x[fault.bit/64] ^= 1 << (fault.bit % 64)
+}
+
+func additionalData128a(s *state, ad []byte) {
+	additionalData128aGeneric(s, ad)
+}
+
+func encryptBlocks128a(s *state, dst, src []byte) {
+	encryptBlocks128aGeneric(s, dst, src)
+}
+
+func decryptBlocks128a(s *state, dst, src []byte) {
+	decryptBlocks128aGeneric(s, dst, src)
+}
+
+// round runs the round with constant C, which identifies the
+// round as C&0xf.
+func round(s *state, C uint64) {
+	roundGeneric(s, C)
+	maybeFault(s, int(C&0xf))
+}
+
+// rounds runs the last n rounds of the 12-round permutation.
+func rounds(s *state, n int) {
+	for r := 12 - n; r < 12; r++ {
+		round(s, uint64((0xf-r)<<4|r))
+	}
+}
+
+func p12(s *state) {
+	rounds(s, 12)
+}
+
+func p8(s *state) {
+	rounds(s, 8)
+}
+
+func p6(s *state) {
+	rounds(s, 6)
+}
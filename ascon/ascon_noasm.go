@@ -1,8 +1,11 @@
-//go:build !(amd64 || arm64 || gc) || purego
-// +build !amd64,!arm64,!gc purego
+//go:build (!(amd64 || arm64) || !gc || purego) && !faultinject
+// +build !amd64,!arm64 !gc purego
+// +build !faultinject
 
 package ascon
 
+const implementation = "generic"
+
 func additionalData128a(s *state, ad []byte) {
 	additionalData128aGeneric(s, ad)
 }
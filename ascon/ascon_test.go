@@ -4,102 +4,1616 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"testing/quick"
+
+	"github.com/ericlagergren/lwcrypto/internal/kat"
 )
 
-var stateType = reflect.TypeOf([5]uint64{})
+var stateType = reflect.TypeOf([5]uint64{})
+
+func randState(rng *rand.Rand) state {
+	v, ok := quick.Value(stateType, rng)
+	if !ok {
+		panic("got false")
+	}
+	x := v.Interface().([5]uint64)
+	return state{
+		x0: x[0],
+		x1: x[1],
+		x2: x[2],
+		x3: x[3],
+		x4: x[4],
+	}
+}
+
+func TestRound(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for i := 0; i < 1000; i++ {
+		s := randState(rng)
+		want, got := s, s
+		C := uint64(i)
+		roundGeneric(&want, C)
+		round(&got, C)
+		if want != got {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestPermute(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		fn        func(*state)
+		fnGeneric func(*state)
+	}{
+		{"p12", p12, p12Generic},
+		{"p8", p8, p8Generic},
+		{"p6", p6, p6Generic},
+	} {
+		rng := rand.New(rand.NewSource(0xDEADBEEF))
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				s := randState(rng)
+				want, got := s, s
+				tc.fnGeneric(&want)
+				tc.fn(&got)
+				if want != got {
+					t.Fatalf("#%d: expected %v, got %v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+// TestPermuteRounds tests that each permutation is equivalent
+// to applying each of its rounds one at a time.
+func TestPermuteRounds(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		nr   int
+		fn   func(*state)
+	}{
+		{"p12", 12, p12Generic},
+		{"p8", 8, p8Generic},
+		{"p6", 6, p6Generic},
+	} {
+		rng := rand.New(rand.NewSource(0xDEADBEEF))
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				s := randState(rng)
+				want, got := s, s
+				for r := 12 - tc.nr; r < 12; r++ {
+					roundGeneric(&want, uint64((0xf-r)<<4|r))
+				}
+				tc.fn(&got)
+				if want != got {
+					t.Fatalf("#%d: expected %v, got %v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVectors128(t *testing.T) {
+	testVectors(t, New128, filepath.Join("testdata", "vectors_128.txt"))
+}
+
+func TestVectors128a(t *testing.T) {
+	testVectors(t, New128a, filepath.Join("testdata", "vectors_128a.txt"))
+}
+
+// TestVectors80pq tests ASCON-80pq against known answer tests
+// generated with the reference implementation.
+func TestVectors80pq(t *testing.T) {
+	testVectors(t, New80pq, filepath.Join("testdata", "vectors_80pq.txt"))
+}
+
+func TestNew80pq(t *testing.T) {
+	key := make([]byte, KeySize80pq)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	plaintext := []byte("hello, world!")
+
+	c, err := New80pq(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := c.Seal(nil, nonce, plaintext, nil)
+
+	// Every bit of the key matters, including those that
+	// ASCON-80pq splits across state words.
+	for i := 0; i < 8*KeySize80pq; i++ {
+		key[i/8] ^= 1 << uint(i%8)
+		c, err := New80pq(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Open(nil, nonce, ciphertext, nil); err == nil {
+			t.Fatalf("#%d: opened with the wrong key", i)
+		}
+		key[i/8] ^= 1 << uint(i%8)
+	}
+
+	// ASCON-128 with the first 16 bytes of the key is a
+	// different AEAD.
+	c128, err := New128(key[:KeySize])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c128.Open(nil, nonce, ciphertext, nil); err == nil {
+		t.Fatal("ASCON-80pq ciphertext opened as ASCON-128")
+	}
+
+	if _, err := c.(*AEAD).MarshalBinary(); err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, n := range []int{0, KeySize, KeySize80pq - 1, KeySize80pq + 1} {
+		if _, err := New80pq(make([]byte, n)); err == nil {
+			t.Fatalf("%d: expected an error", n)
+		}
+	}
+}
+
+// TestVectorsAEAD128 tests Ascon-AEAD128 against known answer
+// tests in the layout of the NIST LWC KATs.
+//
+// The file follows the ascon-c genkat pattern: the key is the
+// bytes 00..0F and the nonce is the bytes 10..1F. Count 1 is
+// checked below against the published asconaead128 KAT. The
+// other vectors were generated with testdata/ascon.py; the
+// explicit cases after Count 1 pin a few of them so that a
+// regenerated file cannot silently drift.
+func TestVectorsAEAD128(t *testing.T) {
+	testVectors(t, NewAEAD128, filepath.Join("testdata", "vectors_aead128.txt"))
+
+	key := unhex("000102030405060708090A0B0C0D0E0F")
+	nonce := unhex("101112131415161718191A1B1C1D1E1F")
+	c, err := NewAEAD128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		count  int
+		pt, ad int
+		ct     string
+	}{
+		// LWC_AEAD_KAT_128_128.txt, Count = 1.
+		{1, 0, 0, "4F9C278211BEC9316BF68F46EE8B2EC6"},
+		// testdata/ascon.py.
+		{2, 0, 1, "7133E5C79505FD75061DF412C0DEA4B9"},
+		{34, 1, 0, "C84C4BC1957CAD5AA2660F67326C05EEB7"},
+		{530, 16, 1, "6207902B37F3A149BCE25D0D9F0D504E9615108DB7F16098D443DD6816B44985"},
+		{1089, 32, 32, "16D2F2A7C74BDA41ADB551F0D6958F801612E3CD0AF14D8AC32B56D25E250769F269B70ADB97C9DBC6A4F0535F802728"},
+	} {
+		want := unhex(tc.ct)
+		got := c.Seal(nil, nonce, kat.Incrementing(tc.pt), kat.Incrementing(tc.ad))
+		if !bytes.Equal(got, want) {
+			t.Fatalf("#%d: expected %#x, got %#x", tc.count, want, got)
+		}
+	}
+	want := unhex("4F9C278211BEC9316BF68F46EE8B2EC6")
+
+	// The v1.2 ASCON-128a is unchanged.
+	legacy, err := New128a(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := legacy.Seal(nil, nonce, nil, nil); bytes.Equal(got, want) {
+		t.Fatal("ASCON-128a matches Ascon-AEAD128")
+	}
+}
+
+// TestAEAD128 tests Ascon-AEAD128 round trips, in-place
+// operation, and forgeries.
+func TestAEAD128(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	c, err := NewAEAD128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < 50; n++ {
+		plaintext := make([]byte, n)
+		ad := make([]byte, n%19)
+		rng.Read(plaintext)
+		rng.Read(ad)
+
+		ciphertext := c.Seal(nil, nonce, plaintext, ad)
+		buf := append([]byte(nil), plaintext...)
+		if got := c.Seal(buf[:0], nonce, buf, ad); !bytes.Equal(got, ciphertext) {
+			t.Fatalf("%d: expected %#x, got %#x", n, ciphertext, got)
+		}
+		got, err := c.Open(ciphertext[:0], nonce, ciphertext, ad)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("%d: expected %#x, got %#x", n, plaintext, got)
+		}
+
+		ciphertext = c.Seal(nil, nonce, plaintext, ad)
+		ciphertext[rng.Intn(len(ciphertext))] ^= 1
+		if _, err := c.Open(nil, nonce, ciphertext, ad); err != errOpen {
+			t.Fatalf("%d: expected %v, got %v", n, errOpen, err)
+		}
+	}
+	if _, err := NewAEAD128(key[1:]); err == nil {
+		t.Fatal("expected an error for a short key")
+	}
+}
+
+func testVectors(t *testing.T, fn func([]byte) (cipher.AEAD, error), path string) {
+	vecs, err := readVecs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vecs {
+		c, err := fn(v.key)
+		if err != nil {
+			t.Fatalf("#%d: %v", i+1, err)
+		}
+		ciphertext := c.Seal(nil, v.nonce, v.pt, v.ad)
+		if !bytes.Equal(ciphertext, v.ct) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v.ct, ciphertext)
+		}
+		plaintext, err := c.Open(nil, v.nonce, v.ct, v.ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", i+1, err)
+		}
+		if !bytes.Equal(plaintext, v.pt) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v.pt, plaintext)
+		}
+	}
+}
+
+// TestAdditionalDataBoundary tests additional data lengths at
+// and around the rate against the reference known answer tests,
+// absorbing the additional data both all at once and in two
+// pieces split at every offset.
+func TestAdditionalDataBoundary(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+		path string
+		lens []int
+	}{
+		{"128", New128, "vectors_128.txt", []int{7, 8, 9}},
+		{"128a", New128a, "vectors_128a.txt", []int{7, 8, 9, 15, 16, 17}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			vecs, err := readVecs(filepath.Join("testdata", tc.path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make(map[int]bool)
+			for _, n := range tc.lens {
+				want[n] = true
+			}
+			seen := make(map[int]bool)
+			for i, v := range vecs {
+				if !want[len(v.ad)] {
+					continue
+				}
+				seen[len(v.ad)] = true
+				c, err := tc.fn(v.key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				aead := c.(*AEAD)
+				ciphertext := aead.Seal(nil, v.nonce, v.pt, v.ad)
+				if !bytes.Equal(ciphertext, v.ct) {
+					t.Fatalf("#%d: expected %#x, got %#x", i+1, v.ct, ciphertext)
+				}
+				for j := 0; j <= len(v.ad); j++ {
+					ad := adChunks{v.ad[:j], v.ad[j:]}
+					ciphertext, err := aead.SealWriterTo(nil, v.nonce, v.pt, &ad)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(ciphertext, v.ct) {
+						t.Fatalf("#%d (split %d): expected %#x, got %#x",
+							i+1, j, v.ct, ciphertext)
+					}
+				}
+				if _, err := aead.Open(nil, v.nonce, v.ct, v.ad); err != nil {
+					t.Fatalf("#%d: %v", i+1, err)
+				}
+			}
+			for _, n := range tc.lens {
+				if !seen[n] {
+					t.Fatalf("no vectors with %d bytes of additional data", n)
+				}
+			}
+		})
+	}
+}
+
+// TestDecryptTail128a tests decrypting every partial final block
+// length against the reference known answer tests.
+func TestDecryptTail128a(t *testing.T) {
+	vecs, err := readVecs(filepath.Join("testdata", "vectors_128a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int]bool)
+	for i, v := range vecs {
+		tail := len(v.pt) % BlockSize128a
+		if tail == 0 {
+			continue
+		}
+		seen[tail] = true
+		c, err := New128a(v.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintext, err := c.Open(nil, v.nonce, v.ct, v.ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", i+1, err)
+		}
+		if !bytes.Equal(plaintext, v.pt) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v.pt, plaintext)
+		}
+	}
+	for n := 1; n < BlockSize128a; n++ {
+		if !seen[n] {
+			t.Fatalf("no vectors with a %d-byte final block", n)
+		}
+	}
+}
+
+// TestDecryptTail tests that decrypting leaves the state exactly
+// as encrypting does for every final block length.
+func TestDecryptTail(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for _, tc := range []struct {
+		name      string
+		blockSize int
+		encrypt   func(*state, []byte, []byte)
+		decrypt   func(*state, []byte, []byte)
+	}{
+		{"128", BlockSize128, (*state).encrypt128, (*state).decrypt128},
+		{"128a", BlockSize128a, (*state).encrypt128a, (*state).decrypt128a},
+	} {
+		for n := 0; n < 3*tc.blockSize; n++ {
+			for i := 0; i < 10; i++ {
+				s := randState(rng)
+				plaintext := make([]byte, n)
+				rng.Read(plaintext)
+
+				s1 := s
+				ciphertext := make([]byte, n)
+				tc.encrypt(&s1, ciphertext, plaintext)
+
+				s2 := s
+				got := make([]byte, n)
+				tc.decrypt(&s2, got, ciphertext)
+
+				if !bytes.Equal(got, plaintext) {
+					t.Fatalf("%s (%d): expected %#x, got %#x",
+						tc.name, n, plaintext, got)
+				}
+				if s1 != s2 {
+					t.Fatalf("%s (%d): expected %#x, got %#x",
+						tc.name, n, s1, s2)
+				}
+			}
+		}
+	}
+}
+
+// TestPad tests the padding helpers at every partial block
+// length.
+func TestPad(t *testing.T) {
+	for n := 0; n < 8; n++ {
+		b := make([]byte, 8)
+		for i := 0; i < n; i++ {
+			b[i] = byte(i + 1)
+		}
+		b[n] = 0x80
+		want := binary.BigEndian.Uint64(b)
+		got := be64n(b[:n]) ^ pad(n)
+		if got != want {
+			t.Fatalf("#%d: expected %#016x, got %#016x", n, want, got)
+		}
+	}
+}
+
+// TestGenerateKAT tests GenerateKAT against the published
+// ascon-c ascon128v12 LWC_AEAD_KAT_128_128.txt.
+//
+// testdata/vectors_128.txt is that file; its vectors match the
+// copy in github.com/cloudflare/circl. A few entries are also
+// listed here, so the check does not depend on the file.
+func TestGenerateKAT(t *testing.T) {
+	gen := GenerateKAT(1089)
+	for _, tc := range []struct {
+		count int
+		ct    string
+	}{
+		{1, "E355159F292911F794CB1432A0103A8A"},
+		{2, "944DF887CD4901614C5DEDBC42FC0DA0"},
+		{34, "BC18C3F4E39ECA7222490D967C79BFFC92"},
+		{1089, "B96C78651B6246B0C3B1A5D373B0D5168DCA4A96734CF0DDF5F92F8D15E30270279BF6A6CC3F2FC9350B915C292BDB8D"},
+	} {
+		want := unhex(tc.ct)
+		if got := gen[tc.count-1].CT; !bytes.Equal(got, want) {
+			t.Fatalf("#%d: expected %#x, got %#x", tc.count, want, got)
+		}
+	}
+
+	vecs, err := readVecs(filepath.Join("testdata", "vectors_128.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != len(gen) {
+		t.Fatalf("expected %d vectors, got %d", len(gen), len(vecs))
+	}
+	for i, v := range vecs {
+		got := gen[i]
+		for _, f := range []struct {
+			name      string
+			want, got []byte
+		}{
+			{"Key", v.key, got.Key},
+			{"Nonce", v.nonce, got.Nonce},
+			{"PT", v.pt, got.PT},
+			{"AD", v.ad, got.AD},
+			{"CT", v.ct, got.CT},
+		} {
+			if !bytes.Equal(f.want, f.got) {
+				t.Fatalf("#%d: %s: expected %#x, got %#x", i+1, f.name, f.want, f.got)
+			}
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ASCON-128 vector must agree with GenerateKAT.
+	const count = selfTestSize*(kat.MaxAD+1) + selfTestSize + 1
+	v := GenerateKAT(count)[count-1]
+	if len(v.PT) != selfTestSize || len(v.AD) != selfTestSize {
+		t.Fatalf("bad vector: %+v", v)
+	}
+	if want := selfTestVectors[0].ct; !bytes.Equal(v.CT, want) {
+		t.Fatalf("expected %#x, got %#x", want, v.CT)
+	}
+
+	// A wrong answer must be reported.
+	for i := range selfTestVectors {
+		ct := selfTestVectors[i].ct
+		selfTestVectors[i].ct = append([]byte(nil), ct...)
+		selfTestVectors[i].ct[0] ^= 1
+		err := SelfTest()
+		selfTestVectors[i].ct = ct
+		if err == nil {
+			t.Fatalf("#%d: expected an error", i)
+		}
+	}
+}
+
+// message is a random input to Seal.
+type message struct {
+	Key, Nonce, Plaintext, AD []byte
+}
+
+var _ quick.Generator = message{}
+
+func (message) Generate(rng *rand.Rand, size int) reflect.Value {
+	m := message{
+		Key:       make([]byte, KeySize),
+		Nonce:     make([]byte, NonceSize),
+		Plaintext: make([]byte, rng.Intn(4*size+1)),
+		AD:        make([]byte, rng.Intn(4*size+1)),
+	}
+	rng.Read(m.Key)
+	rng.Read(m.Nonce)
+	rng.Read(m.Plaintext)
+	rng.Read(m.AD)
+	return reflect.ValueOf(m)
+}
+
+// TestAuthenticate tests that Authenticate returns the same tag
+// as sealing an empty plaintext.
+func TestAuthenticate(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for _, fn := range []func([]byte) (cipher.AEAD, error){
+		New128, New128a, NewWithLengthBlock,
+	} {
+		key := make([]byte, KeySize)
+		rng.Read(key)
+		c, err := fn(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aead := c.(*AEAD)
+		for n := 0; n <= 64; n++ {
+			testAuthenticate(t, rng, aead, n)
+		}
+		testAuthenticate(t, rng, aead, 4096)
+	}
+}
+
+func testAuthenticate(t *testing.T, rng *rand.Rand, aead *AEAD, n int) {
+	nonce := make([]byte, NonceSize)
+	rng.Read(nonce)
+	ad := make([]byte, n)
+	rng.Read(ad)
+
+	want := aead.Seal(nil, nonce, nil, ad)
+	got := aead.Authenticate(nonce, ad)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("#%d: expected %#x, got %#x", n, want, got)
+	}
+	if _, err := aead.Open(nil, nonce, got, ad); err != nil {
+		t.Fatalf("#%d: %v", n, err)
+	}
+}
+
+// TestChecked tests that SealChecked and OpenChecked return
+// errors where Seal and Open panic.
+func TestChecked(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 1
+	nonce := make([]byte, NonceSize)
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	if _, err := aead.SealChecked(nil, nonce[1:], nil, nil); err == nil {
+		t.Fatal("expected an error for a short nonce")
+	}
+	if _, err := aead.OpenChecked(nil, nonce[1:], make([]byte, TagSize), nil); err == nil {
+		t.Fatal("expected an error for a short nonce")
+	}
+
+	buf := make([]byte, 64+TagSize)
+	plaintext := buf[1:33]
+	if _, err := aead.SealChecked(buf[:0], nonce, plaintext, nil); err != ErrBufferOverlap {
+		t.Fatalf("expected %v, got %v", ErrBufferOverlap, err)
+	}
+	mustPanic(t, "ascon: invalid buffer overlap", func() {
+		aead.Seal(buf[:0], nonce, plaintext, nil)
+	})
+
+	// In-place operation is still allowed.
+	ciphertext, err := aead.SealChecked(plaintext[:0], nonce, plaintext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := aead.OpenChecked(buf[:0], nonce, ciphertext, nil); err != ErrBufferOverlap {
+		t.Fatalf("expected %v, got %v", ErrBufferOverlap, err)
+	}
+	mustPanic(t, "ascon: invalid buffer overlap", func() {
+		aead.Open(buf[:0], nonce, ciphertext, nil)
+	})
+	got, err := aead.OpenChecked(ciphertext[:0], nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, make([]byte, len(plaintext))) {
+		t.Fatalf("expected zeros, got %#x", got)
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := func(m message) bool {
+				aead, err := tc.fn(m.Key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ct := aead.Seal(nil, m.Nonce, m.Plaintext, m.AD)
+				pt, err := aead.Open(nil, m.Nonce, ct, m.AD)
+				return err == nil && bytes.Equal(pt, m.Plaintext)
+			}
+			if err := quick.Check(f, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestInPlace tests that Seal and Open work when dst exactly
+// overlaps the input.
+func TestInPlace(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			key := make([]byte, KeySize)
+			nonce := make([]byte, NonceSize)
+			rand.Read(key)
+			rand.Read(nonce)
+			aead, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ad := make([]byte, 13)
+			for ptLen := 0; ptLen < 4*BlockSize128a; ptLen++ {
+				plaintext := make([]byte, ptLen)
+				rand.Read(plaintext)
+				want := aead.Seal(nil, nonce, plaintext, ad)
+
+				buf := make([]byte, ptLen, ptLen+TagSize)
+				copy(buf, plaintext)
+				got := aead.Seal(buf[:0], nonce, buf, ad)
+				if !bytes.Equal(want, got) {
+					t.Fatalf("%d: expected %#x, got %#x", ptLen, want, got)
+				}
+
+				pt, err := aead.Open(got[:0], nonce, got, ad)
+				if err != nil {
+					t.Fatalf("%d: %v", ptLen, err)
+				}
+				if !bytes.Equal(pt, plaintext) {
+					t.Fatalf("%d: expected %#x, got %#x", ptLen, plaintext, pt)
+				}
+			}
+		})
+	}
+}
+
+// TestAppend tests Seal and Open with a non-empty dst, with and
+// without enough capacity.
+func TestAppend(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			key := make([]byte, KeySize)
+			nonce := make([]byte, NonceSize)
+			rand.Read(key)
+			rand.Read(nonce)
+			aead, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prefix := []byte("existing data")
+			ad := make([]byte, 13)
+			rand.Read(ad)
+			for ptLen := 0; ptLen < 2*BlockSize128a+1; ptLen++ {
+				plaintext := make([]byte, ptLen)
+				rand.Read(plaintext)
+				want := aead.Seal(nil, nonce, plaintext, ad)
+
+				n := len(prefix) + ptLen + TagSize
+				for _, c := range []int{len(prefix), n - 1, n, n + 8} {
+					dst := make([]byte, len(prefix), c)
+					copy(dst, prefix)
+					got := aead.Seal(dst, nonce, plaintext, ad)
+					if !bytes.Equal(dst, prefix) || !bytes.Equal(got[:len(prefix)], prefix) {
+						t.Fatalf("(%d, %d): prefix modified", ptLen, c)
+					}
+					if !bytes.Equal(got[len(prefix):], want) {
+						t.Fatalf("(%d, %d): expected %#x, got %#x",
+							ptLen, c, want, got[len(prefix):])
+					}
+
+					dst = make([]byte, len(prefix), c)
+					copy(dst, prefix)
+					pt, err := aead.Open(dst, nonce, want, ad)
+					if err != nil {
+						t.Fatalf("(%d, %d): %v", ptLen, c, err)
+					}
+					if !bytes.Equal(pt[:len(prefix)], prefix) {
+						t.Fatalf("(%d, %d): prefix modified", ptLen, c)
+					}
+					if !bytes.Equal(pt[len(prefix):], plaintext) {
+						t.Fatalf("(%d, %d): expected %#x, got %#x",
+							ptLen, c, plaintext, pt[len(prefix):])
+					}
+				}
+			}
+		})
+	}
+}
+
+// sealBoth seals plaintext with both ASCON-128 and ASCON-128a.
+func sealBoth(key, nonce, plaintext, ad []byte) (ct128, ct128a []byte) {
+	a, err := New128(key)
+	if err != nil {
+		panic(err)
+	}
+	b, err := New128a(key)
+	if err != nil {
+		panic(err)
+	}
+	return a.Seal(nil, nonce, plaintext, ad), b.Seal(nil, nonce, plaintext, ad)
+}
+
+func TestSealBoth(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	a, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New128a(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 1, BlockSize128, BlockSize128a, 100} {
+		plaintext := make([]byte, n)
+		ad := make([]byte, n)
+		rand.Read(plaintext)
+		rand.Read(ad)
+
+		ct128, ct128a := sealBoth(key, nonce, plaintext, ad)
+		if bytes.Equal(ct128, ct128a) {
+			t.Fatalf("%d: ASCON-128 and ASCON-128a ciphertexts are equal", n)
+		}
+		if _, err := a.Open(nil, nonce, ct128, ad); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if _, err := b.Open(nil, nonce, ct128a, ad); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if _, err := a.Open(nil, nonce, ct128a, ad); err != errOpen {
+			t.Fatalf("%d: expected %v, got %v", n, errOpen, err)
+		}
+		if _, err := b.Open(nil, nonce, ct128, ad); err != errOpen {
+			t.Fatalf("%d: expected %v, got %v", n, errOpen, err)
+		}
+	}
+}
+
+func TestRandomSealer(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+
+	for _, n := range []int{0, 1, 16, 100} {
+		random := make([]byte, NonceSize+n)
+		rand.Read(random)
+		r, err := NewRandomSealer(key, bytes.NewReader(random))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sealed, err := r.Seal(n)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if len(sealed) != NonceSize+n+TagSize {
+			t.Fatalf("%d: expected %d bytes, got %d",
+				n, NonceSize+n+TagSize, len(sealed))
+		}
+		if !bytes.Equal(sealed[:NonceSize], random[:NonceSize]) {
+			t.Fatalf("%d: nonce mismatch", n)
+		}
+		got, err := r.Open(sealed)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if !bytes.Equal(got, random[NonceSize:]) {
+			t.Fatalf("%d: expected %#x, got %#x", n, random[NonceSize:], got)
+		}
+
+		// The reader is exhausted.
+		if _, err := r.Seal(1); err == nil {
+			t.Fatalf("%d: expected an error", n)
+		}
+	}
+
+	r, err := NewRandomSealer(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := r.Seal(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Open(sealed); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{-1, math.MinInt32, maxInt - NonceSize - TagSize + 1, maxInt} {
+		if _, err := r.Seal(n); err == nil {
+			t.Fatalf("%d: expected an error", n)
+		}
+	}
+}
+
+func TestSealWithRandomNonce(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+	plaintext := []byte("hello, world!")
+	ad := []byte("additional data")
+
+	// A seeded reader produces the same nonces each time.
+	seal := func() [][]byte {
+		rng := rand.New(rand.NewSource(1))
+		var out [][]byte
+		for i := 0; i < 2; i++ {
+			sealed, err := aead.SealWithRandomNonce(rng, nil, plaintext, ad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, sealed)
+		}
+		return out
+	}
+	a, b := seal(), seal()
+	rng := rand.New(rand.NewSource(1))
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, a[i], b[i])
+		}
+		nonce := make([]byte, NonceSize)
+		rng.Read(nonce)
+		if !bytes.Equal(a[i][:NonceSize], nonce) {
+			t.Fatalf("#%d: expected nonce %#x, got %#x", i, nonce, a[i][:NonceSize])
+		}
+		want := aead.Seal(nonce, nonce, plaintext, ad)
+		if !bytes.Equal(a[i], want) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, want, a[i])
+		}
+		got, err := aead.OpenWithRandomNonce(nil, a[i], ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, plaintext, got)
+		}
+	}
+
+	// Short reads.
+	for _, n := range []int{0, 1, NonceSize - 1} {
+		r := bytes.NewReader(make([]byte, n))
+		if _, err := aead.SealWithRandomNonce(r, nil, plaintext, ad); err != io.ErrUnexpectedEOF {
+			t.Fatalf("%d: expected %v, got %v", n, io.ErrUnexpectedEOF, err)
+		}
+	}
+
+	sealed, err := aead.SealWithRandomNonce(nil, nil, plaintext, ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := aead.OpenWithRandomNonce(nil, sealed, ad); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := aead.OpenWithRandomNonce(nil, sealed[:NonceSize+TagSize-1], ad); err != errOpen {
+		t.Fatalf("expected %v, got %v", errOpen, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	plaintext := []byte("hello, world!")
+	for _, tc := range []struct {
+		level SecurityLevel
+		fn    func([]byte) (cipher.AEAD, error)
+	}{
+		{Robust, New128},
+		{Fast, New128a},
+	} {
+		want, err := tc.fn(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := New(tc.level, key)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.level, err)
+		}
+		wantCt := want.Seal(nil, nonce, plaintext, nil)
+		gotCt := got.Seal(nil, nonce, plaintext, nil)
+		if !bytes.Equal(wantCt, gotCt) {
+			t.Fatalf("%s: expected %#x, got %#x", tc.level, wantCt, gotCt)
+		}
+		if _, err := New(tc.level, key[:KeySize-1]); err == nil {
+			t.Fatalf("%s: expected an error", tc.level)
+		}
+	}
+	for _, level := range []SecurityLevel{0, Fast + 1, -1} {
+		if _, err := New(level, key); err == nil {
+			t.Fatalf("%s: expected an error", level)
+		}
+	}
+}
+
+func TestNewWithDomain(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	plaintext := []byte("hello, world!")
+	ad := []byte("additional data")
+
+	// The zero domain is plain ASCON-128.
+	a, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewWithDomain(key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := a.Seal(nil, nonce, plaintext, ad)
+	got := b.Seal(nil, nonce, plaintext, ad)
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expected %#x, got %#x", want, got)
+	}
+
+	domains := []uint64{1, 2, 1 << 63}
+	for i, d := range domains {
+		a, err := NewWithDomain(key, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext := a.Seal(nil, nonce, plaintext, ad)
+		if _, err := a.Open(nil, nonce, ciphertext, ad); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		for j, d := range append(domains, 0) {
+			if i == j {
+				continue
+			}
+			b, err := NewWithDomain(key, d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := b.Open(nil, nonce, ciphertext, ad); err != errOpen {
+				t.Fatalf("(%d, %d): expected %v, got %v", i, j, errOpen, err)
+			}
+		}
+	}
+}
+
+func TestNewVariantBound(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	plaintext := []byte("hello, world!")
+	ad := []byte("additional data")
+
+	open := func(a cipher.AEAD, ciphertext []byte) error {
+		_, err := a.Open(nil, nonce, ciphertext, ad)
+		return err
+	}
+
+	robust, err := NewVariantBound(key, Robust)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := NewVariantBound(key, Fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct128 := robust.Seal(nil, nonce, plaintext, ad)
+	ct128a := fast.Seal(nil, nonce, plaintext, ad)
+	if err := open(robust, ct128); err != nil {
+		t.Fatalf("Robust: %v", err)
+	}
+	if err := open(fast, ct128a); err != nil {
+		t.Fatalf("Fast: %v", err)
+	}
+	if open(fast, ct128) == nil {
+		t.Fatal("ASCON-128 ciphertext opened as ASCON-128a")
+	}
+	if open(robust, ct128a) == nil {
+		t.Fatal("ASCON-128a ciphertext opened as ASCON-128")
+	}
+
+	// Bound ciphertexts are not compatible with the standard
+	// variants, in either direction.
+	for _, tc := range []struct {
+		level SecurityLevel
+		bound cipher.AEAD
+		ct    []byte
+		fn    func([]byte) (cipher.AEAD, error)
+	}{
+		{Robust, robust, ct128, New128},
+		{Fast, fast, ct128a, New128a},
+	} {
+		std, err := tc.fn(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if open(std, tc.ct) == nil {
+			t.Fatalf("%s: bound ciphertext opened by the standard variant", tc.level)
+		}
+		if open(tc.bound, std.Seal(nil, nonce, plaintext, ad)) == nil {
+			t.Fatalf("%s: standard ciphertext opened by the bound variant", tc.level)
+		}
+	}
+
+	// The standard variants are already separated by their IVs.
+	a, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New128a(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open(b, a.Seal(nil, nonce, plaintext, ad)) == nil {
+		t.Fatal("ASCON-128 ciphertext opened as ASCON-128a")
+	}
+
+	if _, err := NewVariantBound(key[:KeySize-1], Robust); err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, level := range []SecurityLevel{0, Fast + 1, -1} {
+		if _, err := NewVariantBound(key, level); err == nil {
+			t.Fatalf("%s: expected an error", level)
+		}
+	}
+}
+
+func TestNewWithLengthBlock(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+
+	a, err := NewWithLengthBlock(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{0, 1, 7, 8, 9, 33} {
+		plaintext := make([]byte, n)
+		ad := make([]byte, 2*n+1)
+		rand.Read(plaintext)
+		rand.Read(ad)
+
+		ciphertext := a.Seal(nil, nonce, plaintext, ad)
+		got, err := a.Open(nil, nonce, ciphertext, ad)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("%d: expected %#x, got %#x", n, plaintext, got)
+		}
+
+		// Check the encoding of the length block.
+		var s state
+		s.init(iv128,
+			binary.BigEndian.Uint64(key[0:8]),
+			binary.BigEndian.Uint64(key[8:16]),
+			binary.BigEndian.Uint64(nonce[0:8]),
+			binary.BigEndian.Uint64(nonce[8:16]))
+		s.additionalData128(ad)
+		s.encrypt128(make([]byte, n), plaintext)
+		s.x0 ^= uint64(len(ad))
+		p6(&s)
+		s.x0 ^= uint64(n)
+		p6(&s)
+		s.finalize128(binary.BigEndian.Uint64(key[0:8]),
+			binary.BigEndian.Uint64(key[8:16]))
+		tag := make([]byte, TagSize)
+		s.tag(tag)
+		if want := ciphertext[n:]; !bytes.Equal(tag, want) {
+			t.Fatalf("%d: expected %#x, got %#x", n, want, tag)
+		}
+
+		// The streaming additional data path must agree.
+		ct, err := a.(*AEAD).SealWriterTo(nil, nonce, plaintext, bytes.NewReader(ad))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ct, ciphertext) {
+			t.Fatalf("%d: expected %#x, got %#x", n, ciphertext, ct)
+		}
+
+		// The length block is not compatible with ASCON-128.
+		if _, err := std.Open(nil, nonce, ciphertext, ad); err != errOpen {
+			t.Fatalf("%d: expected %v, got %v", n, errOpen, err)
+		}
+
+		// Changing either length must fail.
+		for i, tc := range []struct {
+			ciphertext, ad []byte
+		}{
+			{ciphertext, ad[:len(ad)-1]},
+			{ciphertext, append(ad[:len(ad):len(ad)], 0)},
+			{append(append([]byte{}, ciphertext[:n]...), ciphertext[n+1:]...), ad},
+			{append(append(append([]byte{}, ciphertext[:n]...), 0), ciphertext[n:]...), ad},
+		} {
+			if _, err := a.Open(nil, nonce, tc.ciphertext, tc.ad); err != errOpen {
+				t.Fatalf("(%d, #%d): expected %v, got %v", n, i, errOpen, err)
+			}
+		}
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	key := make([]byte, KeySize)
+	if _, err := NewStrict(key); err != errZeroKey {
+		t.Fatalf("expected %v, got %v", errZeroKey, err)
+	}
+	if _, err := New128(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStrict(key[:KeySize-1]); err == nil || err == errZeroKey {
+		t.Fatalf("expected a key length error, got %v", err)
+	}
+
+	rand.Read(key)
+	if _, err := NewStrict(key); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestOpenNoRelease tests that Open does not leave any
+// plaintext in dst if the ciphertext is not authentic, whether
+// dst is a separate buffer or the ciphertext itself.
+func TestOpenNoRelease(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	ad := []byte("additional data")
+
+	for _, fn := range []func([]byte) (cipher.AEAD, error){New128, New128a} {
+		aead, err := fn(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range []int{1, 7, 8, 16, 17, 1000, 64 * 1024} {
+			plaintext := make([]byte, n)
+			rng.Read(plaintext)
+			for i := range plaintext {
+				// Make sure zero is never a valid plaintext byte.
+				plaintext[i] |= 1
+			}
+			want := aead.Seal(nil, nonce, plaintext, ad)
+			want[len(want)-1-rng.Intn(TagSize)] ^= 1
+
+			dst := make([]byte, n)
+			if _, err := aead.Open(dst[:0], nonce, want, ad); err == nil {
+				t.Fatalf("%d: expected an error", n)
+			}
+			for i, c := range dst {
+				if c != 0 {
+					t.Fatalf("%d: dst[%d] = %#x", n, i, c)
+				}
+			}
 
-func randState(rng *rand.Rand) state {
-	v, ok := quick.Value(stateType, rng)
-	if !ok {
-		panic("got false")
+			ciphertext := append([]byte(nil), want...)
+			if _, err := aead.Open(ciphertext[:0], nonce, ciphertext, ad); err == nil {
+				t.Fatalf("%d: expected an error", n)
+			}
+			for i, c := range ciphertext[:n] {
+				if c != 0 {
+					t.Fatalf("%d: ciphertext[%d] = %#x", n, i, c)
+				}
+			}
+		}
 	}
-	x := v.Interface().([5]uint64)
-	return state{
-		x0: x[0],
-		x1: x[1],
-		x2: x[2],
-		x3: x[3],
-		x4: x[4],
+}
+
+// TestOpenAllocs tests that Open does not allocate if dst has
+// enough capacity.
+func TestOpenAllocs(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		fn      func([]byte) (cipher.AEAD, error)
+		keySize int
+	}{
+		{"128", New128, KeySize},
+		{"128a", New128a, KeySize},
+		{"80pq", New80pq, KeySize80pq},
+	} {
+		aead, err := tc.fn(make([]byte, tc.keySize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := make([]byte, NonceSize)
+		ad := make([]byte, 13)
+		ciphertext := aead.Seal(nil, nonce, make([]byte, 1024), ad)
+		dst := make([]byte, 0, len(ciphertext))
+
+		n := testing.AllocsPerRun(100, func() {
+			if _, err := aead.Open(dst, nonce, ciphertext, ad); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if n != 0 {
+			t.Fatalf("%s: expected zero allocations, got %v", tc.name, n)
+		}
 	}
 }
 
-func TestRound(t *testing.T) {
-	rng := rand.New(rand.NewSource(0xDEADBEEF))
-	for i := 0; i < 1000; i++ {
-		s := randState(rng)
-		want, got := s, s
-		C := uint64(i)
-		roundGeneric(&want, C)
-		round(&got, C)
-		if want != got {
-			t.Fatalf("expected %v, got %v", want, got)
+func TestOpenExact(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	plaintext := []byte("hello, world!")
+	ciphertext := aead.Seal(nil, nonce, plaintext, nil)
+	got, err := aead.OpenExact(nil, nonce, ciphertext, nil, len(plaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("expected %#x, got %#x", plaintext, got)
+	}
+	for _, n := range []int{-1, 0, len(plaintext) - 1, len(plaintext) + 1} {
+		if _, err := aead.OpenExact(nil, nonce, ciphertext, nil, n); err != errLength {
+			t.Fatalf("%d: expected %v, got %v", n, errLength, err)
 		}
 	}
+	if _, err := aead.OpenExact(nil, nonce, ciphertext[:TagSize-1], nil, -1); err != errLength {
+		t.Fatalf("expected %v, got %v", errLength, err)
+	}
+
+	// A length match does not skip authentication.
+	ciphertext[0] ^= 1
+	if _, err := aead.OpenExact(nil, nonce, ciphertext, nil, len(plaintext)); err != errOpen {
+		t.Fatalf("expected %v, got %v", errOpen, err)
+	}
 }
 
-func TestPermute(t *testing.T) {
+func TestCheckPlaintextLen(t *testing.T) {
+	// Plaintexts this large cannot be allocated, so test the
+	// check directly.
+	checkPlaintextLen(0)
+	checkPlaintextLen(maxInt - TagSize)
+	mustPanic(t, "ascon: plaintext too large", func() {
+		checkPlaintextLen(maxInt - TagSize + 1)
+	})
+	mustPanic(t, "ascon: plaintext too large", func() {
+		checkPlaintextLen(maxInt)
+	})
+}
+
+func TestStats(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := New128a(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	// Nothing is counted by default.
+	aead.Seal(nil, nonce, make([]byte, 7), make([]byte, 3))
+	if got := aead.Stats(); got != (Stats{}) {
+		t.Fatalf("expected zero stats, got %+v", got)
+	}
+	aead.EnableStats()
+
+	var want Stats
+	for i := 0; i < 10; i++ {
+		plaintext := make([]byte, i*7)
+		ad := make([]byte, i*3)
+		ciphertext := aead.Seal(nil, nonce, plaintext, ad)
+		want.Plaintext += uint64(len(plaintext))
+		want.AdditionalData += uint64(len(ad))
+		if got := aead.Stats(); got != want {
+			t.Fatalf("#%d: expected %+v, got %+v", i, want, got)
+		}
+
+		if _, err := aead.Open(nil, nonce, ciphertext, ad); err != nil {
+			t.Fatal(err)
+		}
+		want.Plaintext += uint64(len(plaintext))
+		want.AdditionalData += uint64(len(ad))
+		if got := aead.Stats(); got != want {
+			t.Fatalf("#%d: expected %+v, got %+v", i, want, got)
+		}
+
+		// Failed opens are counted too.
+		ciphertext[0] ^= 1
+		if _, err := aead.Open(nil, nonce, ciphertext, ad); err == nil {
+			t.Fatalf("#%d: expected an error", i)
+		}
+		want.Plaintext += uint64(len(plaintext))
+		want.AdditionalData += uint64(len(ad))
+		if got := aead.Stats(); got != want {
+			t.Fatalf("#%d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// Stats is safe for concurrent use.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				aead.Seal(nil, nonce, make([]byte, 5), make([]byte, 3))
+				aead.Stats()
+			}
+		}()
+	}
+	wg.Wait()
+	want.Plaintext += 4 * 100 * 5
+	want.AdditionalData += 4 * 100 * 3
+	if got := aead.Stats(); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestNonceSize(t *testing.T) {
+	key := make([]byte, KeySize)
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+	ad := bytes.NewReader(nil)
+
+	for _, n := range []int{0, NonceSize - 1, NonceSize + 1} {
+		nonce := make([]byte, n)
+		want := (&NonceSizeError{Got: n, Want: NonceSize}).Error()
+		for name, fn := range map[string]func(){
+			"Seal": func() { aead.Seal(nil, nonce, nil, nil) },
+			"Open": func() { aead.Open(nil, nonce, make([]byte, TagSize), nil) },
+			"OpenExact": func() {
+				aead.OpenExact(nil, nonce, make([]byte, TagSize), nil, 0)
+			},
+			"SealWriterTo": func() { aead.SealWriterTo(nil, nonce, nil, ad) },
+			"OpenWriterTo": func() {
+				aead.OpenWriterTo(nil, nonce, make([]byte, TagSize), ad)
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				mustPanic(t, want, fn)
+			})
+		}
+	}
+}
+
+func TestResetCounters(t *testing.T) {
+	defer func(n uint64) {
+		maxKeyUsage = n
+	}(maxKeyUsage)
+	maxKeyUsage = 100
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	aead.Seal(nil, nonce, make([]byte, 60), make([]byte, 40))
+	mustPanic(t, "ascon: key usage limit exceeded", func() {
+		aead.Seal(nil, nonce, make([]byte, 1), nil)
+	})
+	mustPanic(t, "ascon: key usage limit exceeded", func() {
+		aead.SealWriterTo(nil, nonce, make([]byte, 1), bytes.NewReader(nil))
+	})
+	mustPanic(t, "ascon: key usage limit exceeded", func() {
+		aead.SealStream(io.Discard, bytes.NewReader(make([]byte, 1)), nonce, nil)
+	})
+
+	aead.ResetCounters()
+	if got := aead.Stats(); got != (Stats{}) {
+		t.Fatalf("expected zero stats, got %+v", got)
+	}
+	ciphertext := aead.Seal(nil, nonce, make([]byte, 60), make([]byte, 40))
+	if _, err := aead.Open(nil, nonce, ciphertext, make([]byte, 40)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDestroy(t *testing.T) {
 	for _, tc := range []struct {
-		name      string
-		fn        func(*state)
-		fnGeneric func(*state)
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
 	}{
-		{"p12", p12, p12Generic},
-		{"p8", p8, p8Generic},
-		{"p6", p6, p6Generic},
+		{"128", New128},
+		{"128a", New128a},
 	} {
-		rng := rand.New(rand.NewSource(0xDEADBEEF))
 		t.Run(tc.name, func(t *testing.T) {
-			for i := 0; i < 1000; i++ {
-				s := randState(rng)
-				want, got := s, s
-				tc.fnGeneric(&want)
-				tc.fn(&got)
-				if want != got {
-					t.Fatalf("#%d: expected %v, got %v", i, want, got)
-				}
+			key := make([]byte, KeySize)
+			nonce := make([]byte, NonceSize)
+			c, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
 			}
+			ciphertext := c.Seal(nil, nonce, nil, nil)
+			c.(*AEAD).Destroy()
+
+			mustPanic(t, "ascon: use after Destroy", func() {
+				c.Seal(nil, nonce, nil, nil)
+			})
+			mustPanic(t, "ascon: use after Destroy", func() {
+				c.Open(nil, nonce, ciphertext, nil)
+			})
 		})
 	}
 }
 
-func TestVectors128(t *testing.T) {
-	testVectors(t, New128, filepath.Join("testdata", "vectors_128.txt"))
+func TestNewWithIV(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+
+	key := make([]byte, KeySize)
+	iv := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(iv)
+
+	p, err := NewWithIV(key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("hello, world!")
+	ad := []byte("header")
+	seen := make(map[string]bool)
+	for _, pn := range []uint64{0, 1, 2, 255, 256, 1<<32 - 1, 1 << 62} {
+		nonce := make([]byte, NonceSize)
+		copy(nonce, iv)
+		for i := 0; i < 8; i++ {
+			nonce[NonceSize-1-i] ^= byte(pn >> (8 * i))
+		}
+		want := aead.Seal(nil, nonce, plaintext, ad)
+		got := p.Seal(nil, pn, plaintext, ad)
+		if !bytes.Equal(want, got) {
+			t.Fatalf("%d: expected %#x, got %#x", pn, want, got)
+		}
+		if seen[string(got)] {
+			t.Fatalf("%d: duplicate ciphertext", pn)
+		}
+		seen[string(got)] = true
+
+		pt, err := p.Open(nil, pn, got, ad)
+		if err != nil {
+			t.Fatalf("%d: %v", pn, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("%d: expected %#x, got %#x", pn, plaintext, pt)
+		}
+		if _, err := p.Open(nil, pn+1, got, ad); err == nil {
+			t.Fatalf("%d: opened with the wrong packet number", pn)
+		}
+	}
+
+	if _, err := NewWithIV(key, iv[:NonceSize-1]); err == nil {
+		t.Fatal("expected an error for a short IV")
+	}
 }
 
-func TestVectors128a(t *testing.T) {
-	testVectors(t, New128a, filepath.Join("testdata", "vectors_128a.txt"))
+// sameKey reports, in constant time, whether a and b have the
+// same key.
+//
+// Tests should use it instead of comparing keys directly.
+func sameKey(a, b *AEAD) bool {
+	var x, y [KeySize]byte
+	binary.BigEndian.PutUint64(x[0:8], a.k0)
+	binary.BigEndian.PutUint64(x[8:16], a.k1)
+	binary.BigEndian.PutUint64(y[0:8], b.k0)
+	binary.BigEndian.PutUint64(y[8:16], b.k1)
+	return subtle.ConstantTimeCompare(x[:], y[:]) == 1
 }
 
-func testVectors(t *testing.T, fn func([]byte) (cipher.AEAD, error), path string) {
-	vecs, err := readVecs(path)
+func TestSameKey(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	a, err := New128(key)
 	if err != nil {
 		t.Fatal(err)
 	}
-	for i, v := range vecs {
-		c, err := fn(v.key)
+	// ASCON-128a with the same key has the same key.
+	b, err := New128a(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameKey(a.(*AEAD), b.(*AEAD)) {
+		t.Fatal("expected the same key")
+	}
+	for i := range key {
+		other := append([]byte(nil), key...)
+		other[i] ^= 0x80
+		b, err := New128(other)
 		if err != nil {
-			t.Fatalf("#%d: %v", i+1, err)
+			t.Fatal(err)
 		}
-		ciphertext := c.Seal(nil, v.nonce, v.pt, v.ad)
-		if !bytes.Equal(ciphertext, v.ct) {
-			t.Fatalf("#%d: expected %#x, got %#x", i+1, v.ct, ciphertext)
+		if sameKey(a.(*AEAD), b.(*AEAD)) {
+			t.Fatalf("#%d: expected different keys", i)
 		}
-		plaintext, err := c.Open(nil, v.nonce, v.ct, v.ad)
-		if err != nil {
-			t.Fatalf("#%d: %v", i+1, err)
+	}
+}
+
+func mustPanic(t *testing.T, msg string, fn func()) {
+	t.Helper()
+
+	defer func() {
+		t.Helper()
+
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected panic %q", msg)
 		}
-		if !bytes.Equal(plaintext, v.pt) {
-			t.Fatalf("#%d: expected %#x, got %#x", i+1, v.pt, plaintext)
+		if r != msg {
+			t.Fatalf("expected panic %q, got %v", msg, r)
+		}
+	}()
+	fn()
+}
+
+func BenchmarkP12(b *testing.B) {
+	benchmarkPermute(b, p12)
+}
+
+func BenchmarkP12Generic(b *testing.B) {
+	benchmarkPermute(b, p12Generic)
+}
+
+func BenchmarkP12Rounds(b *testing.B) {
+	benchmarkPermute(b, func(s *state) {
+		for r := 0; r < 12; r++ {
+			round(s, uint64((0xf-r)<<4|r))
 		}
+	})
+}
+
+func BenchmarkP6(b *testing.B) {
+	benchmarkPermute(b, p6)
+}
+
+func BenchmarkP6Generic(b *testing.B) {
+	benchmarkPermute(b, p6Generic)
+}
+
+func benchmarkPermute(b *testing.B, fn func(*state)) {
+	var s state
+	for i := 0; i < b.N; i++ {
+		fn(&s)
 	}
 }
 
@@ -119,10 +1633,38 @@ func BenchmarkOpen8K_128a(b *testing.B) {
 	benchmarkOpen(b, New128a, make([]byte, 8*1024))
 }
 
+func BenchmarkSeal8_128(b *testing.B) {
+	benchmarkSeal(b, New128, make([]byte, 8))
+}
+
 func BenchmarkSeal1K_128(b *testing.B) {
 	benchmarkSeal(b, New128, make([]byte, 1024))
 }
 
+// BenchmarkSealParallel8_128 measures small messages sealed
+// concurrently with one key, which is where shared usage
+// counters are most expensive.
+func BenchmarkSealParallel8_128(b *testing.B) {
+	b.SetBytes(8)
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	aead, err := New128(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		buf := make([]byte, 8)
+		ad := make([]byte, 13)
+		var out []byte
+		for pb.Next() {
+			out = aead.Seal(out[:0], nonce, buf, ad)
+		}
+	})
+}
+
 func BenchmarkOpen1K_128(b *testing.B) {
 	benchmarkOpen(b, New128, make([]byte, 1024))
 }
@@ -135,6 +1677,55 @@ func BenchmarkOpen8K_128(b *testing.B) {
 	benchmarkOpen(b, New128, make([]byte, 8*1024))
 }
 
+func BenchmarkSeal64K_128a(b *testing.B) {
+	benchmarkSeal(b, New128a, make([]byte, 64*1024))
+}
+
+func BenchmarkOpen64K_128a(b *testing.B) {
+	benchmarkOpen(b, New128a, make([]byte, 64*1024))
+}
+
+func BenchmarkAuthenticate4K(b *testing.B) {
+	benchmarkAuthenticate(b, New128, make([]byte, 4*1024))
+}
+
+// BenchmarkSealAD4K is Seal with only additional data. Compare
+// with BenchmarkAuthenticate4K.
+func BenchmarkSealAD4K(b *testing.B) {
+	b.SetBytes(4 * 1024)
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	ad := make([]byte, 4*1024)
+	aead, err := New128(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var out []byte
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = aead.Seal(out[:0], nonce, nil, ad)
+	}
+}
+
+func benchmarkAuthenticate(b *testing.B, fn func([]byte) (cipher.AEAD, error), ad []byte) {
+	b.SetBytes(int64(len(ad)))
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := fn(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		aead.Authenticate(nonce, ad)
+	}
+}
+
 func benchmarkSeal(b *testing.B, fn func([]byte) (cipher.AEAD, error), buf []byte) {
 	b.SetBytes(int64(len(buf)))
 
@@ -175,6 +1766,14 @@ func benchmarkOpen(b *testing.B, fn func([]byte) (cipher.AEAD, error), buf []byt
 	}
 }
 
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
 type vector struct {
 	key   []byte
 	nonce []byte
@@ -236,3 +1835,73 @@ func readVecs(path string) ([]vector, error) {
 	}
 	return vecs, nil
 }
+
+func TestMarshalBinary(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	plaintext := []byte("hello, world!")
+	ad := []byte("additional data")
+
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+		alg  byte
+	}{
+		{"128", New128, algASCON128},
+		{"128a", New128a, algASCON128a},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			aead, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blob, err := aead.(*AEAD).MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := append([]byte{marshalVersion, tc.alg}, key...)
+			if !bytes.Equal(blob, want) {
+				t.Fatalf("expected %#x, got %#x", want, blob)
+			}
+
+			var b AEAD
+			if err := b.UnmarshalBinary(blob); err != nil {
+				t.Fatal(err)
+			}
+			if !sameKey(aead.(*AEAD), &b) {
+				t.Fatal("expected the same key")
+			}
+			got := b.Seal(nil, nonce, plaintext, ad)
+			if want := aead.Seal(nil, nonce, plaintext, ad); !bytes.Equal(got, want) {
+				t.Fatalf("expected %#x, got %#x", want, got)
+			}
+
+			for i, bad := range [][]byte{
+				nil,
+				blob[:len(blob)-1],
+				append(blob[:len(blob):len(blob)], 0),
+				append([]byte{marshalVersion + 1}, blob[1:]...),
+				append([]byte{marshalVersion, 0}, blob[2:]...),
+			} {
+				if err := b.UnmarshalBinary(bad); err == nil {
+					t.Fatalf("#%d: expected an error", i)
+				}
+			}
+		})
+	}
+
+	for name, fn := range map[string]func() (cipher.AEAD, error){
+		"NewWithDomain":      func() (cipher.AEAD, error) { return NewWithDomain(key, 1) },
+		"NewWithLengthBlock": func() (cipher.AEAD, error) { return NewWithLengthBlock(key) },
+	} {
+		aead, err := fn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := aead.(*AEAD).MarshalBinary(); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
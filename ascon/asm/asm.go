@@ -11,7 +11,7 @@ import (
 
 func main() {
 	Package("github.com/ericlagergren/lwcrypto/ascon")
-	ConstraintExpr("gc,!purego")
+	ConstraintExpr("gc,!purego,!faultinject")
 
 	declarePermute()
 	declareRound()
@@ -0,0 +1,33 @@
+package ascon
+
+// Authenticate returns the tag over additionalData without
+// encrypting anything.
+//
+// The tag is identical to the one returned by
+//
+//	Seal(nil, nonce, nil, additionalData)
+//
+// so it can be verified with Open by passing the tag as the
+// ciphertext. Authenticate skips the plaintext path entirely,
+// but absorbing the additional data dominates the cost, so for
+// large headers it is about as fast as Seal. Its advantage is
+// that it returns only the tag.
+//
+// As with Seal, a nonce must never be reused with the same key,
+// including between Seal and Authenticate.
+func (a *AEAD) Authenticate(nonce, additionalData []byte) []byte {
+	checkNonce(nonce)
+	var s state
+	a.init(&s, nonce)
+	a.additionalData(&s, additionalData)
+	if a.count(uint64(len(additionalData)), 0) {
+		panic("ascon: key usage limit exceeded")
+	}
+	// An empty plaintext is only the padding block, which is
+	// absorbed into x0 without a permutation.
+	s.x0 ^= pad(0)
+	a.finalize(&s, uint64(len(additionalData)), 0)
+	tag := make([]byte, TagSize)
+	s.tag(tag)
+	return tag
+}
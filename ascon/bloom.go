@@ -0,0 +1,147 @@
+package ascon
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+)
+
+var errNonceReuse = errors.New("ascon: nonce possibly reused")
+
+// BloomNonceGuard wraps an AEAD and rejects nonces that were
+// probably already used to seal a message.
+//
+// It records each nonce in a Bloom filter, so its memory use
+// does not depend on the number of nonces. In exchange, it is
+// probabilistic: it never misses a nonce that was actually
+// reused, but it rejects a small fraction of fresh nonces. The
+// false positive rate is fpRate once expectedN nonces have been
+// used and grows beyond that.
+//
+// It is safe for concurrent use.
+type BloomNonceGuard struct {
+	aead cipher.AEAD
+
+	mu sync.Mutex
+	// bits is the filter.
+	bits []uint64
+	// m is the number of bits in the filter.
+	m uint64
+	// k is the number of hash functions.
+	k int
+}
+
+// NewBloomNonceGuard creates a BloomNonceGuard for about
+// expectedN nonces with a false positive rate of fpRate.
+//
+// expectedN must be positive and fpRate must be in (0, 1).
+func NewBloomNonceGuard(aead cipher.AEAD, expectedN int, fpRate float64) (*BloomNonceGuard, error) {
+	if expectedN <= 0 {
+		return nil, errors.New("ascon: invalid number of nonces")
+	}
+	if !(fpRate > 0 && fpRate < 1) {
+		return nil, errors.New("ascon: invalid false positive rate")
+	}
+	// m = -n*ln(p) / ln(2)^2 and k = (m/n)*ln(2).
+	n := float64(expectedN)
+	m := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	if m > math.MaxInt32*64 {
+		return nil, errors.New("ascon: Bloom filter too large")
+	}
+	k := int(math.Round(m / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &BloomNonceGuard{
+		aead: aead,
+		bits: make([]uint64, (uint64(m)+63)/64),
+		m:    uint64(m),
+		k:    k,
+	}, nil
+}
+
+// NonceSize returns the size of the nonce that must be passed
+// to Seal and Open.
+func (g *BloomNonceGuard) NonceSize() int {
+	return g.aead.NonceSize()
+}
+
+// Overhead returns the maximum difference between the lengths
+// of a plaintext and its ciphertext.
+func (g *BloomNonceGuard) Overhead() int {
+	return g.aead.Overhead()
+}
+
+// Seal is like cipher.AEAD.Seal, but returns an error without
+// encrypting anything if nonce was probably already used.
+func (g *BloomNonceGuard) Seal(dst, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if err := validateNonce(nonce, g.aead.NonceSize()); err != nil {
+		panic(err.Error())
+	}
+	if !g.add(nonce) {
+		return nil, errNonceReuse
+	}
+	return g.aead.Seal(dst, nonce, plaintext, additionalData), nil
+}
+
+// Open is the same as cipher.AEAD.Open. It does not check or
+// record the nonce.
+func (g *BloomNonceGuard) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	return g.aead.Open(dst, nonce, ciphertext, additionalData)
+}
+
+// add adds nonce to the filter and reports whether it was
+// absent.
+func (g *BloomNonceGuard) add(nonce []byte) bool {
+	h1, h2 := bloomHash(nonce)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	absent := false
+	for i := 0; i < g.k; i++ {
+		j := (h1 + uint64(i)*h2) % g.m
+		w, b := j/64, uint64(1)<<(j%64)
+		if g.bits[w]&b == 0 {
+			absent = true
+			g.bits[w] |= b
+		}
+	}
+	return absent
+}
+
+// contains reports whether nonce is probably in the filter.
+func (g *BloomNonceGuard) contains(nonce []byte) bool {
+	h1, h2 := bloomHash(nonce)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i := 0; i < g.k; i++ {
+		j := (h1 + uint64(i)*h2) % g.m
+		if g.bits[j/64]&(1<<(j%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash returns the two hashes of nonce used to derive the
+// filter indices.
+//
+// The indices are h1 + i*h2 for i in [0, k), per Kirsch and
+// Mitzenmacher.
+func bloomHash(nonce []byte) (h1, h2 uint64) {
+	var x sponge
+	x.init(hashIV(0))
+	x.absorb(nonce)
+	var buf [16]byte
+	x.squeeze(buf[:])
+	h1 = binary.BigEndian.Uint64(buf[0:8])
+	// An even h2 would visit fewer distinct indices when m is
+	// even.
+	h2 = binary.BigEndian.Uint64(buf[8:16]) | 1
+	return h1, h2
+}
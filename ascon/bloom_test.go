@@ -0,0 +1,97 @@
+package ascon
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBloomNonceGuard(t *testing.T) {
+	const (
+		n      = 10_000
+		fpRate = 0.01
+	)
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	aead, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewBloomNonceGuard(aead, n, fpRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	nonces := make([][]byte, n)
+	for i := range nonces {
+		nonces[i] = make([]byte, NonceSize)
+		rng.Read(nonces[i])
+		// A fresh nonce can be a false positive.
+		g.Seal(nil, nonces[i], nil, nil)
+	}
+
+	// Every reused nonce must be caught.
+	for i, nonce := range nonces {
+		if _, err := g.Seal(nil, nonce, nil, nil); err != errNonceReuse {
+			t.Fatalf("#%d: expected %v, got %v", i, errNonceReuse, err)
+		}
+	}
+
+	// Measure the false positive rate with fresh nonces.
+	const trials = 100_000
+	fp := 0
+	nonce := make([]byte, NonceSize)
+	for i := 0; i < trials; i++ {
+		rng.Read(nonce)
+		if g.contains(nonce) {
+			fp++
+		}
+	}
+	rate := float64(fp) / trials
+	t.Logf("false positive rate: %.4f", rate)
+	if rate > 2*fpRate {
+		t.Fatalf("false positive rate too high: %.4f", rate)
+	}
+}
+
+func TestBloomNonceGuardSeal(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	aead, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewBloomNonceGuard(aead, 100, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := g.Seal(nil, nonce, []byte("hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Open(nil, nonce, ciphertext, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Open does not record the nonce, so opening the same
+	// ciphertext twice is fine.
+	if _, err := g.Open(nil, nonce, ciphertext, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		n  int
+		fp float64
+	}{
+		{0, 0.01},
+		{-1, 0.01},
+		{100, 0},
+		{100, 1},
+		{100, -0.5},
+	} {
+		if _, err := NewBloomNonceGuard(aead, tc.n, tc.fp); err == nil {
+			t.Fatalf("(%d, %v): expected an error", tc.n, tc.fp)
+		}
+	}
+}
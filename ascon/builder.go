@@ -0,0 +1,72 @@
+package ascon
+
+import "io"
+
+// AEADBuilder assembles additional data from multiple chunks
+// before sealing or opening a single message.
+//
+// The chunks are absorbed in order as if they were one
+// contiguous slice of additional data. They are not copied, so
+// they must not be modified until Seal or Open returns.
+//
+// An AEADBuilder is reusable: Seal and Open discard the
+// additional data afterward.
+type AEADBuilder struct {
+	aead *AEAD
+	ad   adChunks
+}
+
+// Builder returns an AEADBuilder for a.
+func (a *AEAD) Builder() *AEADBuilder {
+	return &AEADBuilder{aead: a}
+}
+
+// AD appends p to the additional data and returns b.
+func (b *AEADBuilder) AD(p []byte) *AEADBuilder {
+	b.ad = append(b.ad, p)
+	return b
+}
+
+// Seal is like AEAD.Seal, except that the additional data is
+// the concatenation of each chunk passed to AD.
+func (b *AEADBuilder) Seal(dst, nonce, plaintext []byte) []byte {
+	defer b.reset()
+	out, err := b.aead.SealWriterTo(dst, nonce, plaintext, &b.ad)
+	if err != nil {
+		// adChunks.WriteTo never fails.
+		panic(err)
+	}
+	return out
+}
+
+// Open is like AEAD.Open, except that the additional data is
+// the concatenation of each chunk passed to AD.
+func (b *AEADBuilder) Open(dst, nonce, ciphertext []byte) ([]byte, error) {
+	defer b.reset()
+	return b.aead.OpenWriterTo(dst, nonce, ciphertext, &b.ad)
+}
+
+// reset discards the additional data.
+func (b *AEADBuilder) reset() {
+	for i := range b.ad {
+		b.ad[i] = nil
+	}
+	b.ad = b.ad[:0]
+}
+
+// adChunks is additional data split into chunks.
+type adChunks [][]byte
+
+var _ io.WriterTo = (*adChunks)(nil)
+
+func (c *adChunks) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, p := range *c {
+		m, err := w.Write(p)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,68 @@
+package ascon
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"math/rand"
+	"testing"
+)
+
+func TestAEADBuilder(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			key := make([]byte, KeySize)
+			nonce := make([]byte, NonceSize)
+			rand.Read(key)
+			rand.Read(nonce)
+			c, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			aead := c.(*AEAD)
+			b := aead.Builder()
+
+			plaintext := []byte("hello, world!")
+			for _, chunks := range [][]string{
+				{},
+				{"", "", ""},
+				{"header", "", "field"},
+				{"0123456789abcdef", "0", "123456789abcdef01234"},
+			} {
+				var ad []byte
+				for _, s := range chunks {
+					b.AD([]byte(s))
+					ad = append(ad, s...)
+				}
+				want := aead.Seal(nil, nonce, plaintext, ad)
+				got := b.Seal(nil, nonce, plaintext)
+				if !bytes.Equal(got, want) {
+					t.Fatalf("%q: expected %#x, got %#x", chunks, want, got)
+				}
+
+				// The builder is reset after Seal.
+				for _, s := range chunks {
+					b.AD([]byte(s))
+				}
+				pt, err := b.Open(nil, nonce, want)
+				if err != nil {
+					t.Fatalf("%q: %v", chunks, err)
+				}
+				if !bytes.Equal(pt, plaintext) {
+					t.Fatalf("%q: expected %#x, got %#x", chunks, plaintext, pt)
+				}
+			}
+
+			// Different additional data must fail.
+			ct := aead.Seal(nil, nonce, plaintext, []byte("abc"))
+			if _, err := b.AD([]byte("ab")).AD([]byte("d")).Open(nil, nonce, ct); err != errOpen {
+				t.Fatalf("expected %v, got %v", errOpen, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package ascon
+
+import "sync"
+
+// Chain is an ASCON-128 AEAD for sessions where each message
+// must depend on the one before it.
+//
+// Seal authenticates the tag of the previously sealed message
+// along with the additional data, and Open does the same with
+// the tag of the previously opened message. As a result, a
+// message only opens if every message before it was opened, in
+// order. Dropped, reordered, replayed, and injected messages
+// all fail to open, without explicit sequence numbers.
+//
+// The sealing and opening chains are independent, so a single
+// Chain can be used at either end of a one-way stream. A
+// bidirectional session should use one Chain, and one key, per
+// direction.
+//
+// A message that fails to open does not advance the chain, so
+// a retransmission of the expected message still opens. A
+// message that is lost for good breaks the chain: every later
+// message fails to open until both sides call Reset.
+//
+// It is safe for concurrent use, but concurrent calls to Seal
+// (or Open) are serialized and chained in an unspecified order.
+type Chain struct {
+	aead AEAD
+
+	mu       sync.Mutex
+	sealPrev [TagSize]byte
+	openPrev [TagSize]byte
+}
+
+// NewChain creates a Chain.
+//
+// The chains start out empty: the first message of each is
+// bound to an all-zero tag.
+func NewChain(key []byte) (*Chain, error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	return &Chain{aead: *a}, nil
+}
+
+// Overhead returns the maximum difference between the lengths
+// of a plaintext and its ciphertext.
+func (c *Chain) Overhead() int {
+	return c.aead.Overhead()
+}
+
+// Seal encrypts and authenticates plaintext, authenticates the
+// additional data and the previous message, and appends the
+// result to dst, returning the updated slice.
+func (c *Chain) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ad := adChunks{c.sealPrev[:], additionalData}
+	out, _ := c.aead.SealWriterTo(dst, nonce, plaintext, &ad)
+	copy(c.sealPrev[:], out[len(out)-TagSize:])
+	return out
+}
+
+// Open decrypts and authenticates ciphertext, authenticates the
+// additional data and the previous message, and, if successful,
+// appends the resulting plaintext to dst, returning the updated
+// slice.
+//
+// The chain only advances if Open succeeds.
+func (c *Chain) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < TagSize {
+		return nil, errOpen
+	}
+	// Copy the tag first in case the plaintext overwrites the
+	// ciphertext.
+	var tag [TagSize]byte
+	copy(tag[:], ciphertext[len(ciphertext)-TagSize:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ad := adChunks{c.openPrev[:], additionalData}
+	out, err := c.aead.OpenWriterTo(dst, nonce, ciphertext, &ad)
+	if err != nil {
+		return nil, err
+	}
+	c.openPrev = tag
+	return out, nil
+}
+
+// Reset empties both chains, starting a new session.
+//
+// Both sides must reset at the same point in the message
+// sequence, for example after re-establishing a connection.
+// The key does not change, so nonces must still not be reused
+// across sessions.
+func (c *Chain) Reset() {
+	c.mu.Lock()
+	c.sealPrev = [TagSize]byte{}
+	c.openPrev = [TagSize]byte{}
+	c.mu.Unlock()
+}
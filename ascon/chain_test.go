@@ -0,0 +1,126 @@
+package ascon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	ad := []byte("additional data")
+	nonce := func(i int) []byte {
+		n := make([]byte, NonceSize)
+		binary.BigEndian.PutUint64(n[8:], uint64(i))
+		return n
+	}
+	// seal seals n messages using the nonces first, first+1,
+	// and so on. Every message in the test uses a distinct
+	// nonce since the key never changes.
+	seal := func(c *Chain, first, n int) (plaintexts, ciphertexts [][]byte) {
+		for i := 0; i < n; i++ {
+			pt := []byte(fmt.Sprintf("message %d", first+i))
+			plaintexts = append(plaintexts, pt)
+			ciphertexts = append(ciphertexts, c.Seal(nil, nonce(first+i), pt, ad))
+		}
+		return
+	}
+	newChain := func() *Chain {
+		c, err := NewChain(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	sender := newChain()
+	plaintexts, ciphertexts := seal(sender, 0, 5)
+
+	// In order.
+	receiver := newChain()
+	for i, ct := range ciphertexts {
+		got, err := receiver.Open(nil, nonce(i), ct, ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(got, plaintexts[i]) {
+			t.Fatalf("#%d: expected %q, got %q", i, plaintexts[i], got)
+		}
+	}
+	// Replayed.
+	if _, err := receiver.Open(nil, nonce(4), ciphertexts[4], ad); err == nil {
+		t.Fatal("replayed message opened")
+	}
+
+	// Reordered.
+	receiver = newChain()
+	if _, err := receiver.Open(nil, nonce(0), ciphertexts[0], ad); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := receiver.Open(nil, nonce(2), ciphertexts[2], ad); err == nil {
+		t.Fatal("reordered message opened")
+	}
+	// The failure did not advance the chain.
+	for i := 1; i < len(ciphertexts); i++ {
+		if _, err := receiver.Open(nil, nonce(i), ciphertexts[i], ad); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+	}
+
+	// Dropped: everything after the gap fails.
+	receiver = newChain()
+	if _, err := receiver.Open(nil, nonce(0), ciphertexts[0], ad); err != nil {
+		t.Fatal(err)
+	}
+	for i := 2; i < len(ciphertexts); i++ {
+		if _, err := receiver.Open(nil, nonce(i), ciphertexts[i], ad); err == nil {
+			t.Fatalf("#%d: opened after a dropped message", i)
+		}
+	}
+
+	// A message sealed without a chain does not open.
+	aead, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver = newChain()
+	if _, err := receiver.Open(nil, nonce(5), aead.Seal(nil, nonce(5), plaintexts[0], ad), ad); err == nil {
+		t.Fatal("unchained message opened")
+	}
+
+	// Reset starts a new session on both sides.
+	sender.Reset()
+	receiver.Reset()
+	plaintexts, ciphertexts = seal(sender, 6, 3)
+	for i, ct := range ciphertexts {
+		got, err := receiver.Open(nil, nonce(6+i), ct, ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(got, plaintexts[i]) {
+			t.Fatalf("#%d: expected %q, got %q", i, plaintexts[i], got)
+		}
+	}
+
+	// In place.
+	sender, receiver = newChain(), newChain()
+	for i := 0; i < 3; i++ {
+		buf := []byte("in place")
+		ct := sender.Seal(buf[:0], nonce(9+i), buf, ad)
+		got, err := receiver.Open(ct[:0], nonce(9+i), ct, ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if string(got) != "in place" {
+			t.Fatalf("#%d: got %q", i, got)
+		}
+	}
+
+	if _, err := newChain().Open(nil, nonce(0), make([]byte, TagSize-1), ad); err == nil {
+		t.Fatal("expected an error")
+	}
+}
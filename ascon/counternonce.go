@@ -0,0 +1,40 @@
+package ascon
+
+import (
+	"errors"
+	"strconv"
+)
+
+// CounterNonce returns the NonceSize-byte nonce
+//
+//	prefix || BE(counter)
+//
+// where the counter is encoded big-endian in the low width
+// bytes and prefix fills the remaining NonceSize-width bytes.
+// The prefix is typically fixed per sender, so that senders
+// sharing a key never produce the same nonce.
+//
+// width must be in [1, NonceSize] and len(prefix) must be
+// NonceSize-width. CounterNonce returns an error if counter
+// does not fit in width bytes: wrapping around would repeat
+// a nonce.
+func CounterNonce(prefix []byte, counter uint64, width int) ([]byte, error) {
+	if width < 1 || width > NonceSize {
+		return nil, errors.New("ascon: invalid counter width: " + strconv.Itoa(width))
+	}
+	if len(prefix) != NonceSize-width {
+		return nil, errors.New("ascon: counter nonce prefix must be " +
+			strconv.Itoa(NonceSize-width) + " bytes")
+	}
+	if width < 8 && counter>>(8*uint(width)) != 0 {
+		return nil, errors.New("ascon: counter overflows " +
+			strconv.Itoa(width) + "-byte field")
+	}
+	nonce := make([]byte, NonceSize)
+	copy(nonce, prefix)
+	for i := NonceSize - 1; i >= NonceSize-width && counter != 0; i-- {
+		nonce[i] = byte(counter)
+		counter >>= 8
+	}
+	return nonce, nil
+}
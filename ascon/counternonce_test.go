@@ -0,0 +1,68 @@
+package ascon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestCounterNonce(t *testing.T) {
+	for width := 1; width <= NonceSize; width++ {
+		prefix := bytes.Repeat([]byte{0xa5}, NonceSize-width)
+
+		seen := make(map[string]bool)
+		for ctr := uint64(0); ctr < 300; ctr++ {
+			if width == 1 && ctr > 0xff {
+				break
+			}
+			nonce, err := CounterNonce(prefix, ctr, width)
+			if err != nil {
+				t.Fatalf("(%d, %d): %v", width, ctr, err)
+			}
+			if len(nonce) != NonceSize {
+				t.Fatalf("(%d, %d): got length %d", width, ctr, len(nonce))
+			}
+			if !bytes.Equal(nonce[:NonceSize-width], prefix) {
+				t.Fatalf("(%d, %d): prefix changed: %#x", width, ctr, nonce)
+			}
+			var want [NonceSize]byte
+			binary.BigEndian.PutUint64(want[NonceSize-8:], ctr)
+			if !bytes.Equal(nonce[NonceSize-width:], want[NonceSize-width:]) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x",
+					width, ctr, want[NonceSize-width:], nonce[NonceSize-width:])
+			}
+			if seen[string(nonce)] {
+				t.Fatalf("(%d, %d): duplicate nonce %#x", width, ctr, nonce)
+			}
+			seen[string(nonce)] = true
+		}
+
+		if width < 8 {
+			max := uint64(1)<<(8*uint(width)) - 1
+			if _, err := CounterNonce(prefix, max, width); err != nil {
+				t.Fatalf("%d: %v", width, err)
+			}
+			if _, err := CounterNonce(prefix, max+1, width); err == nil {
+				t.Fatalf("%d: expected an overflow error", width)
+			}
+		} else {
+			nonce, err := CounterNonce(prefix, ^uint64(0), width)
+			if err != nil {
+				t.Fatalf("%d: %v", width, err)
+			}
+			if got := binary.BigEndian.Uint64(nonce[NonceSize-8:]); got != ^uint64(0) {
+				t.Fatalf("%d: expected %#x, got %#x", width, ^uint64(0), got)
+			}
+		}
+
+		if _, err := CounterNonce(prefix[:len(prefix)/2], 0, width); err == nil && len(prefix) > 0 {
+			t.Fatalf("%d: expected a prefix length error", width)
+		}
+	}
+
+	for _, width := range []int{-1, 0, NonceSize + 1} {
+		if _, err := CounterNonce(nil, 0, width); err == nil {
+			t.Fatalf("%d: expected an error", width)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package ascon
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// NewCTR creates an unauthenticated ASCON-128 stream cipher.
+//
+// WARNING: the stream cipher provides confidentiality only. It
+// has no tag, so an attacker can flip any bit of the ciphertext
+// without detection. Use New128 unless integrity is provided by
+// some other means.
+//
+// The key stream is the rate part of the ASCON-128 state after
+// the key and nonce are absorbed with empty additional data,
+// permuted with p6 after each 8-byte block. ASCON-128 feeds each
+// ciphertext block back into the state, so its key stream
+// depends on the plaintext and no plaintext-independent key
+// stream can match it in general. This key stream matches the
+// one Seal uses for the first block of any plaintext and for
+// every block of an all-zero plaintext: XORKeyStream over n zero
+// bytes equals the first n bytes of Seal's output with the same
+// key and nonce and no additional data.
+//
+// As with the AEAD, a key, nonce pair must never be reused,
+// including between NewCTR and New128.
+func NewCTR(key, nonce []byte) (cipher.Stream, error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateNonce(nonce, NonceSize); err != nil {
+		return nil, err
+	}
+	c := &ctr{off: BlockSize128}
+	a.init(&c.s, nonce)
+	c.s.additionalData128(nil)
+	return c, nil
+}
+
+// ctr implements cipher.Stream.
+type ctr struct {
+	s state
+	// ks is the current block of key stream.
+	ks [BlockSize128]byte
+	// off is the number of bytes of ks that have been used.
+	off int
+}
+
+var _ cipher.Stream = (*ctr)(nil)
+
+func (c *ctr) XORKeyStream(dst, src []byte) {
+	if len(src) == 0 {
+		return
+	}
+	if len(dst) < len(src) {
+		panic("ascon: output smaller than input")
+	}
+	dst = dst[:len(src)]
+	if subtle.InexactOverlap(dst, src) {
+		panic("ascon: invalid buffer overlap")
+	}
+
+	// Remaining key stream.
+	for c.off < BlockSize128 && len(src) > 0 {
+		dst[0] = src[0] ^ c.ks[c.off]
+		c.off++
+		src = src[1:]
+		dst = dst[1:]
+	}
+
+	for len(src) >= BlockSize128 {
+		v := binary.BigEndian.Uint64(src)
+		binary.BigEndian.PutUint64(dst, v^c.s.x0)
+		p6(&c.s)
+		src = src[BlockSize128:]
+		dst = dst[BlockSize128:]
+	}
+
+	if len(src) > 0 {
+		binary.BigEndian.PutUint64(c.ks[:], c.s.x0)
+		p6(&c.s)
+		for i := range src {
+			dst[i] = src[i] ^ c.ks[i]
+		}
+		c.off = len(src)
+	}
+}
@@ -0,0 +1,111 @@
+package ascon
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// TestCTRSeal tests that the key stream matches the ciphertext
+// of an all-zero plaintext.
+func TestCTRSeal(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	for i := 0; i < 100; i++ {
+		rng.Read(key)
+		rng.Read(nonce)
+
+		zero := make([]byte, i)
+		aead, err := New128(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := aead.Seal(nil, nonce, zero, nil)[:i]
+
+		c, err := NewCTR(key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, i)
+		c.XORKeyStream(got, zero)
+		if !bytes.Equal(want, got) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+		}
+
+		// The first block matches for any plaintext.
+		pt := make([]byte, i)
+		rng.Read(pt)
+		want = aead.Seal(nil, nonce, pt, nil)
+		c, err = NewCTR(key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.XORKeyStream(got, pt)
+		n := i
+		if n > BlockSize128 {
+			n = BlockSize128
+		}
+		if !bytes.Equal(want[:n], got[:n]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, want[:n], got[:n])
+		}
+	}
+}
+
+// TestCTRSplit tests that splitting XORKeyStream calls does not
+// change the key stream.
+func TestCTRSplit(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+
+	src := make([]byte, 1000)
+	rng.Read(src)
+
+	c, err := NewCTR(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, len(src))
+	c.XORKeyStream(want, src)
+
+	for i := 0; i < 100; i++ {
+		c, err := NewCTR(key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, len(src))
+		for j := 0; j < len(src); {
+			n := rng.Intn(20) + 1
+			if n > len(src)-j {
+				n = len(src) - j
+			}
+			c.XORKeyStream(got[j:j+n], src[j:j+n])
+			j += n
+		}
+		if !bytes.Equal(want, got) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+		}
+
+		// Decryption is the same operation.
+		c, err = NewCTR(key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.XORKeyStream(got, got)
+		if !bytes.Equal(got, src) {
+			t.Fatalf("#%d: round trip failed", i)
+		}
+	}
+}
+
+func TestNewCTR(t *testing.T) {
+	if _, err := NewCTR(make([]byte, KeySize-1), make([]byte, NonceSize)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := NewCTR(make([]byte, KeySize), make([]byte, NonceSize-1)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,40 @@
+package ascon
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// DeriveAEAD derives an ASCON-128a AEAD for channelID from
+// master.
+//
+// The key is the first KeySize bytes of ASCON-Xof over master
+// followed by the big-endian encoding of channelID. Each
+// channel gets an independent key, so messages sealed for one
+// channel cannot be opened by another even if their nonces
+// collide. Since the key is deterministic, each channel must
+// still use unique nonces.
+//
+// master must be at least KeySize bytes long and should be
+// uniformly random.
+func DeriveAEAD(master []byte, channelID uint32) (cipher.AEAD, error) {
+	if len(master) < KeySize {
+		return nil, errors.New("ascon: master key too short")
+	}
+	var id [4]byte
+	binary.BigEndian.PutUint32(id[:], channelID)
+
+	var x sponge
+	x.init(hashIV(0))
+	x.absorb(master)
+	x.absorb(id[:])
+	var key [KeySize]byte
+	x.squeeze(key[:])
+
+	aead, err := New128a(key[:])
+	for i := range key {
+		key[i] = 0
+	}
+	return aead, err
+}
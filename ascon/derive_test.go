@@ -0,0 +1,73 @@
+package ascon
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestDeriveAEAD(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	master := make([]byte, 32)
+	rng.Read(master)
+	nonce := make([]byte, NonceSize)
+	plaintext := []byte("hello, world")
+
+	// Derivation is deterministic.
+	a1, err := DeriveAEAD(master, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := DeriveAEAD(master, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameKey(a1.(*AEAD), a2.(*AEAD)) {
+		t.Fatal("same channel produced different keys")
+	}
+	if a1.(*AEAD).iv != iv128a {
+		t.Fatal("expected an ASCON-128a AEAD")
+	}
+
+	// Distinct channels cannot open each other's messages.
+	const channels = 8
+	var aeads []*AEAD
+	for id := uint32(0); id < channels; id++ {
+		c, err := DeriveAEAD(master, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aeads = append(aeads, c.(*AEAD))
+	}
+	for i, a := range aeads {
+		ciphertext := a.Seal(nil, nonce, plaintext, nil)
+		for j, b := range aeads {
+			got, err := b.Open(nil, nonce, ciphertext, nil)
+			if i == j {
+				if err != nil {
+					t.Fatalf("#%d: %v", i, err)
+				}
+				if !bytes.Equal(got, plaintext) {
+					t.Fatalf("#%d: expected %q, got %q", i, plaintext, got)
+				}
+			} else if err == nil {
+				t.Fatalf("#%d: opened with channel %d", i, j)
+			}
+		}
+	}
+
+	// Different masters produce different keys.
+	other := append([]byte(nil), master...)
+	other[0] ^= 1
+	c, err := DeriveAEAD(other, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameKey(c.(*AEAD), a1.(*AEAD)) {
+		t.Fatal("different masters produced the same key")
+	}
+
+	if _, err := DeriveAEAD(master[:KeySize-1], 1); err == nil {
+		t.Fatal("expected an error for a short master key")
+	}
+}
@@ -0,0 +1,78 @@
+package ascon
+
+import (
+	"errors"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// SealDetached is like Seal, but returns the tag separately
+// instead of appending it to the ciphertext.
+//
+// The ciphertext is appended to dst. dst and plaintext may
+// overlap exactly or not at all.
+func (a *AEAD) SealDetached(dst, nonce, plaintext, additionalData []byte) (ciphertext, tag []byte) {
+	checkNonce(nonce)
+	checkPlaintextLen(len(plaintext))
+	ret, out := subtle.SliceForAppend(dst, len(plaintext))
+	if subtle.InexactOverlap(out, plaintext) {
+		panic("ascon: invalid buffer overlap")
+	}
+
+	var s state
+	a.init(&s, nonce)
+	a.additionalData(&s, additionalData)
+	if a.count(uint64(len(additionalData)), uint64(len(plaintext))) {
+		panic("ascon: key usage limit exceeded")
+	}
+	tag = make([]byte, TagSize)
+	a.sealDetached(&s, out, tag, plaintext, uint64(len(additionalData)))
+	return ret, tag
+}
+
+// SealDetachedInPlace encrypts buf in place and returns the
+// tag.
+//
+// It is the same as SealDetached(buf[:0], nonce, buf,
+// additionalData), which is useful for fixed-size record
+// buffers: the ciphertext overwrites the plaintext and nothing
+// is copied.
+func (a *AEAD) SealDetachedInPlace(buf, nonce, additionalData []byte) (tag []byte) {
+	_, tag = a.SealDetached(buf[:0], nonce, buf, additionalData)
+	return tag
+}
+
+// OpenDetached is like Open, but takes the tag separately from
+// the ciphertext.
+//
+// The plaintext is appended to dst. dst and ciphertext may
+// overlap exactly or not at all, so OpenDetached(ciphertext[:0],
+// nonce, ciphertext, tag, additionalData) decrypts in place.
+func (a *AEAD) OpenDetached(dst, nonce, ciphertext, tag, additionalData []byte) ([]byte, error) {
+	checkNonce(nonce)
+	if len(tag) != TagSize {
+		return nil, errOpen
+	}
+
+	var s state
+	a.init(&s, nonce)
+	a.additionalData(&s, additionalData)
+	a.count(uint64(len(additionalData)), uint64(len(ciphertext)))
+	return a.openDetached(&s, dst, ciphertext, tag, uint64(len(additionalData)))
+}
+
+// SplitTag splits the output of Seal into the ciphertext and
+// the tag, for use with OpenDetached or to store them apart.
+//
+// Both are sub-slices of sealed. The capacity of ciphertext is
+// limited to its length, so appending to it does not overwrite
+// the tag.
+//
+// SplitTag returns an error if sealed is shorter than TagSize.
+func SplitTag(sealed []byte) (ciphertext, tag []byte, err error) {
+	if len(sealed) < TagSize {
+		return nil, nil, errors.New("ascon: sealed message shorter than tag")
+	}
+	n := len(sealed) - TagSize
+	return sealed[:n:n], sealed[n:], nil
+}
@@ -0,0 +1,122 @@
+package ascon
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"math/rand"
+	"testing"
+)
+
+func TestDetached(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		fn      func([]byte) (cipher.AEAD, error)
+		keySize int
+	}{
+		{"128", New128, KeySize},
+		{"128a", New128a, KeySize},
+		{"LengthBlock", NewWithLengthBlock, KeySize},
+		{"80pq", New80pq, KeySize80pq},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(0xDEADBEEF))
+			key := make([]byte, tc.keySize)
+			nonce := make([]byte, NonceSize)
+			rng.Read(key)
+			rng.Read(nonce)
+			c, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			aead := c.(*AEAD)
+
+			for n := 0; n < 50; n++ {
+				plaintext := make([]byte, n)
+				ad := make([]byte, n%11)
+				rng.Read(plaintext)
+				rng.Read(ad)
+				want := aead.Seal(nil, nonce, plaintext, ad)
+
+				ciphertext, tag := aead.SealDetached(nil, nonce, plaintext, ad)
+				if got := append(ciphertext, tag...); !bytes.Equal(got, want) {
+					t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+				}
+
+				buf := append([]byte(nil), plaintext...)
+				tag = aead.SealDetachedInPlace(buf, nonce, ad)
+				if got := append(buf[:n:n], tag...); !bytes.Equal(got, want) {
+					t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+				}
+
+				got, err := aead.OpenDetached(buf[:0], nonce, buf, tag, ad)
+				if err != nil {
+					t.Fatalf("%d: %v", n, err)
+				}
+				if !bytes.Equal(got, plaintext) || !bytes.Equal(buf, plaintext) {
+					t.Fatalf("%d: expected %#x, got %#x", n, plaintext, buf)
+				}
+
+				// Forgeries fail and do not leave plaintext
+				// behind.
+				aead.SealDetachedInPlace(buf, nonce, ad)
+				tag[rng.Intn(TagSize)] ^= 1
+				if _, err := aead.OpenDetached(buf[:0], nonce, buf, tag, ad); err != errOpen {
+					t.Fatalf("%d: expected %v, got %v", n, errOpen, err)
+				}
+				if !bytes.Equal(buf, make([]byte, n)) {
+					t.Fatalf("%d: expected zeros, got %#x", n, buf)
+				}
+				if _, err := aead.OpenDetached(nil, nonce, nil, tag[:TagSize-1], ad); err != errOpen {
+					t.Fatalf("%d: expected %v, got %v", n, errOpen, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitTag(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	c, err := New128a(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+	ad := []byte("additional data")
+
+	for n := 0; n < 100; n++ {
+		plaintext := make([]byte, n)
+		rng.Read(plaintext)
+		sealed := aead.Seal(nil, nonce, plaintext, ad)
+		ciphertext, tag, err := SplitTag(sealed)
+		if err != nil {
+			t.Fatalf("#%d: %v", n, err)
+		}
+		if len(ciphertext) != n || len(tag) != TagSize {
+			t.Fatalf("#%d: got lengths (%d, %d)", n, len(ciphertext), len(tag))
+		}
+		got, err := aead.OpenDetached(nil, nonce, ciphertext, tag, ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("#%d: expected %#x, got %#x", n, plaintext, got)
+		}
+
+		// Appending to the ciphertext must not clobber the tag.
+		want := append([]byte(nil), tag...)
+		_ = append(ciphertext, 0xff)
+		if !bytes.Equal(tag, want) {
+			t.Fatalf("#%d: tag was overwritten", n)
+		}
+	}
+
+	for n := 0; n < TagSize; n++ {
+		if _, _, err := SplitTag(make([]byte, n)); err == nil {
+			t.Fatalf("#%d: expected an error", n)
+		}
+	}
+}
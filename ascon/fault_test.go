@@ -0,0 +1,51 @@
+//go:build faultinject
+// +build faultinject
+
+package ascon
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// TestInjectFault tests that a single-bit fault in any round of
+// the permutation causes authentication to fail.
+func TestInjectFault(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	aead, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("hello, world!")
+	ad := []byte("additional data")
+	want := aead.Seal(nil, nonce, plaintext, ad)
+
+	for r := 0; r < 12; r++ {
+		for _, bit := range []int{0, 63, 64, 150, 255, 319} {
+			// A fault while sealing.
+			InjectFault(r, bit)
+			ct := aead.Seal(nil, nonce, plaintext, ad)
+			if bytes.Equal(ct, want) {
+				t.Fatalf("(%d, %d): fault had no effect", r, bit)
+			}
+			if _, err := aead.Open(nil, nonce, ct, ad); err != errOpen {
+				t.Fatalf("(%d, %d): expected %v, got %v", r, bit, errOpen, err)
+			}
+
+			// A fault while opening.
+			InjectFault(r, bit)
+			if _, err := aead.Open(nil, nonce, want, ad); err != errOpen {
+				t.Fatalf("(%d, %d): expected %v, got %v", r, bit, errOpen, err)
+			}
+
+			// The fault only fires once.
+			if _, err := aead.Open(nil, nonce, want, ad); err != nil {
+				t.Fatalf("(%d, %d): %v", r, bit, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package ascon
+
+// FingerprintSize is the size in bytes of a fingerprint.
+const FingerprintSize = TagSize
+
+// fingerprintDomain separates fingerprints from ASCON-128 tags
+// computed with the same key. It is "ascon-fp" in ASCII.
+const fingerprintDomain = 0x6173636f6e2d6670
+
+// Fingerprint returns a keyed fingerprint of data.
+//
+// Fingerprints with the same key can be compared to find
+// duplicate data, but fingerprints with different keys are
+// unrelated, so using a key per tenant prevents correlating data
+// across tenants.
+//
+// The fingerprint is the ASCON-128 tag over data as additional
+// data, with an all-zero nonce, no plaintext, and a distinct
+// domain (see NewWithDomain). Short data needs only the
+// initialization and finalization permutations plus one
+// permutation per 8-byte block.
+//
+// Fingerprint panics if key is not KeySize bytes long.
+func Fingerprint(key, data []byte) [FingerprintSize]byte {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		panic(err.Error())
+	}
+	a.domain = fingerprintDomain
+
+	var s state
+	var nonce [NonceSize]byte
+	a.init(&s, nonce[:])
+	a.additionalData(&s, data)
+	var fp [FingerprintSize]byte
+	a.seal(&s, fp[:0], nil, uint64(len(data)))
+	return fp
+}
@@ -0,0 +1,74 @@
+package ascon
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	rng.Read(key)
+
+	for _, n := range []int{0, 1, 8, 16, 17, 100} {
+		data := make([]byte, n)
+		rng.Read(data)
+
+		// The same key and data always produce the same
+		// fingerprint.
+		want := Fingerprint(key, data)
+		if got := Fingerprint(key, data); got != want {
+			t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+		}
+
+		// It is not an ordinary ASCON-128 tag.
+		aead, err := New128(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag := aead.Seal(nil, make([]byte, NonceSize), nil, data)
+		if string(tag) == string(want[:]) {
+			t.Fatalf("%d: fingerprint is the ASCON-128 tag", n)
+		}
+	}
+
+	mustPanic(t, "ascon: bad key length", func() {
+		Fingerprint(key[1:], nil)
+	})
+}
+
+// TestFingerprintKeys tests that the fingerprints of the same
+// data under different keys look unrelated.
+func TestFingerprintKeys(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	data := []byte("the same data for every tenant")
+
+	const trials = 1000
+	k1 := make([]byte, KeySize)
+	k2 := make([]byte, KeySize)
+	total := 0
+	for i := 0; i < trials; i++ {
+		rng.Read(k1)
+		copy(k2, k1)
+		// Keys that differ in a single bit.
+		k2[rng.Intn(KeySize)] ^= 1 << rng.Intn(8)
+
+		a := Fingerprint(k1, data)
+		b := Fingerprint(k2, data)
+		d := 0
+		for j := range a {
+			d += bits.OnesCount8(a[j] ^ b[j])
+		}
+		if d == 0 {
+			t.Fatalf("#%d: identical fingerprints", i)
+		}
+		total += d
+	}
+
+	// On average, half of the 128 bits should differ.
+	avg := float64(total) / trials
+	if avg < 62 || avg > 66 {
+		t.Fatalf("unexpected average Hamming distance: %.2f", avg)
+	}
+}
@@ -0,0 +1,132 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	// frameMagic identifies a frame.
+	frameMagic = "ASCF"
+	// frameVersion is the version of the frame format.
+	frameVersion = 1
+)
+
+var (
+	errFrame        = errors.New("ascon: malformed frame")
+	errFrameVersion = errors.New("ascon: unsupported frame version")
+)
+
+// SealFrame seals plaintext and additional data with ASCON-128
+// under a random nonce and returns a self-contained frame
+//
+//	magic || version || nonceLen || nonce || adLen || ad || ctLen || ciphertext || tag
+//
+// where magic is "ASCF", version and nonceLen are one byte
+// each, and adLen and ctLen are 32-bit big-endian integers.
+// ctLen includes the tag.
+//
+// The additional data is stored in the frame so that OpenFrame
+// can return it. Callers that do not want to retain any
+// additional data can pass nil.
+func SealFrame(key, plaintext, additionalData []byte) ([]byte, error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(additionalData)) > math.MaxUint32 ||
+		uint64(len(plaintext)) > math.MaxUint32-TagSize {
+		return nil, errors.New("ascon: frame too large")
+	}
+	var nonce [NonceSize]byte
+	if _, err := io.ReadFull(defaultRand, nonce[:]); err != nil {
+		return nil, err
+	}
+
+	n := len(frameMagic) + 2 + NonceSize +
+		4 + len(additionalData) +
+		4 + len(plaintext) + TagSize
+	b := make([]byte, 0, n)
+	b = append(b, frameMagic...)
+	b = append(b, frameVersion, NonceSize)
+	b = append(b, nonce[:]...)
+	b = appendUint32(b, uint32(len(additionalData)))
+	b = append(b, additionalData...)
+	b = appendUint32(b, uint32(len(plaintext)+TagSize))
+	return a.Seal(b, nonce[:], plaintext, additionalData), nil
+}
+
+// OpenFrame parses and opens a frame created by SealFrame,
+// returning the plaintext and additional data.
+//
+// The frame must be exactly one frame long: trailing data is an
+// error.
+func OpenFrame(key, frame []byte) (plaintext, additionalData []byte, err error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := frame
+	if len(b) < len(frameMagic)+2 || string(b[:len(frameMagic)]) != frameMagic {
+		return nil, nil, errFrame
+	}
+	b = b[len(frameMagic):]
+	if b[0] != frameVersion {
+		return nil, nil, errFrameVersion
+	}
+	if b[1] != NonceSize {
+		return nil, nil, errFrame
+	}
+	b = b[2:]
+
+	nonce, b, ok := readBytes(b, NonceSize)
+	if !ok {
+		return nil, nil, errFrame
+	}
+	ad, b, ok := readLengthPrefixed(b)
+	if !ok {
+		return nil, nil, errFrame
+	}
+	ciphertext, b, ok := readLengthPrefixed(b)
+	if !ok || len(b) != 0 || len(ciphertext) < TagSize {
+		return nil, nil, errFrame
+	}
+
+	plaintext, err = a.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ad) > 0 {
+		additionalData = append([]byte(nil), ad...)
+	}
+	return plaintext, additionalData, nil
+}
+
+// readLengthPrefixed reads a 32-bit big-endian length followed by
+// that many bytes.
+func readLengthPrefixed(b []byte) (v, rest []byte, ok bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, false
+	}
+	return readBytes(b[4:], int(n))
+}
+
+// readBytes reads n bytes from b.
+func readBytes(b []byte, n int) (v, rest []byte, ok bool) {
+	if len(b) < n {
+		return nil, nil, false
+	}
+	return b[:n:n], b[n:], true
+}
+
+// appendUint32 appends the big-endian encoding of v to b.
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
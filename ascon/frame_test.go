@@ -0,0 +1,110 @@
+package ascon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestFrame(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 1
+
+	for _, tc := range []struct {
+		plaintext []byte
+		ad        []byte
+	}{
+		{nil, nil},
+		{[]byte("hello, world"), nil},
+		{nil, []byte("header")},
+		{[]byte("hello, world"), []byte("header")},
+		{make([]byte, 1000), make([]byte, 300)},
+	} {
+		frame, err := SealFrame(key, tc.plaintext, tc.ad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintext, ad, err := OpenFrame(key, frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(plaintext, tc.plaintext) {
+			t.Fatalf("expected %#x, got %#x", tc.plaintext, plaintext)
+		}
+		if !bytes.Equal(ad, tc.ad) {
+			t.Fatalf("expected %#x, got %#x", tc.ad, ad)
+		}
+
+		// The ciphertext is ordinary ASCON-128 output.
+		c, err := New128(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := frame[6 : 6+NonceSize]
+		want := c.Seal(nil, nonce, tc.plaintext, tc.ad)
+		if !bytes.HasSuffix(frame, want) {
+			t.Fatalf("expected suffix %#x, got %#x", want, frame)
+		}
+	}
+
+	frame, err := SealFrame(key, []byte("hello, world"), []byte("header"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := SealFrame(key, []byte("hello, world"), []byte("header"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(frame, other) {
+		t.Fatal("nonce was reused")
+	}
+
+	// Truncated frames.
+	for i := 0; i < len(frame); i++ {
+		if _, _, err := OpenFrame(key, frame[:i]); err == nil {
+			t.Fatalf("%d: expected an error", i)
+		}
+	}
+	// Trailing data.
+	if _, _, err := OpenFrame(key, append(frame[:len(frame):len(frame)], 0)); err == nil {
+		t.Fatal("expected an error for trailing data")
+	}
+
+	// Tampered fields.
+	adLenOff := 6 + NonceSize
+	ctLenOff := adLenOff + 4 + len("header")
+	for _, tc := range []struct {
+		name string
+		fn   func(b []byte)
+	}{
+		{"magic", func(b []byte) { b[0] ^= 1 }},
+		{"version", func(b []byte) { b[4]++ }},
+		{"nonce length", func(b []byte) { b[5]-- }},
+		{"nonce", func(b []byte) { b[6] ^= 1 }},
+		{"short ad length", func(b []byte) { addUint32(b[adLenOff:], -1) }},
+		{"long ad length", func(b []byte) { addUint32(b[adLenOff:], 1) }},
+		{"huge ad length", func(b []byte) { binary.BigEndian.PutUint32(b[adLenOff:], 0xffffffff) }},
+		{"ad", func(b []byte) { b[adLenOff+4] ^= 1 }},
+		{"short ciphertext length", func(b []byte) { addUint32(b[ctLenOff:], -1) }},
+		{"long ciphertext length", func(b []byte) { addUint32(b[ctLenOff:], 1) }},
+		{"ciphertext", func(b []byte) { b[ctLenOff+4] ^= 1 }},
+		{"tag", func(b []byte) { b[len(b)-1] ^= 1 }},
+	} {
+		b := append([]byte(nil), frame...)
+		tc.fn(b)
+		if _, _, err := OpenFrame(key, b); err == nil {
+			t.Fatalf("%s: expected an error", tc.name)
+		}
+	}
+
+	// Wrong key.
+	key[0] ^= 1
+	if _, _, err := OpenFrame(key, frame); err == nil {
+		t.Fatal("expected an error for the wrong key")
+	}
+}
+
+// addUint32 adds d to the big-endian integer at the start of b.
+func addUint32(b []byte, d int32) {
+	binary.BigEndian.PutUint32(b, binary.BigEndian.Uint32(b)+uint32(d))
+}
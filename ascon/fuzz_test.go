@@ -11,6 +11,7 @@ import (
 
 	"github.com/ericlagergren/lwcrypto/ascon"
 	ref "github.com/ericlagergren/lwcrypto/ascon/internal/asconc/ref"
+	ref80pq "github.com/ericlagergren/lwcrypto/ascon/internal/asconc/ref80pq"
 	refa "github.com/ericlagergren/lwcrypto/ascon/internal/asconc/refa"
 	rand "github.com/ericlagergren/saferand"
 )
@@ -26,9 +27,18 @@ func TestFuzz(t *testing.T) {
 
 		testFuzz(t, refa.New, ascon.New128a)
 	})
+	t.Run("80pq", func(t *testing.T) {
+		t.Parallel()
+
+		testFuzzKey(t, ref80pq.New, ascon.New80pq, ascon.KeySize80pq)
+	})
 }
 
 func testFuzz(t *testing.T, ref, test func([]byte) (cipher.AEAD, error)) {
+	testFuzzKey(t, ref, test, ascon.KeySize)
+}
+
+func testFuzzKey(t *testing.T, ref, test func([]byte) (cipher.AEAD, error), keySize int) {
 	d := 2 * time.Second
 	if testing.Short() {
 		d = 10 * time.Millisecond
@@ -42,7 +52,7 @@ func testFuzz(t *testing.T, ref, test func([]byte) (cipher.AEAD, error)) {
 	}
 	tm := time.NewTimer(d)
 
-	key := make([]byte, ascon.KeySize)
+	key := make([]byte, keySize)
 	nonce := make([]byte, ascon.NonceSize)
 	plaintext := make([]byte, 1*1024*1024) // 1 MB
 	for i := 0; ; i++ {
@@ -0,0 +1,243 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"strconv"
+
+	"github.com/ericlagergren/subtle"
+)
+
+const (
+	// HashSize is the size in bytes of an ASCON-Hash digest.
+	HashSize = 32
+	// HashBlockSize is the rate in bytes of ASCON-Hash.
+	HashBlockSize = 8
+)
+
+// hashIV returns the initialization vector for a hash with
+// a digest of n bits.
+//
+// The IV encodes the key size (zero), rate, number of rounds,
+// and output size in bits. ASCON-Xof uses an output size of
+// zero.
+func hashIV(n int) uint64 {
+	return 0x00400c0000000000 | uint64(n)
+}
+
+// NewHash creates a hash.Hash that computes ASCON-Hash digests
+// of digestBits bits.
+//
+// digestBits must be 128, 160, or 256. NewHash(256) is the
+// standard ASCON-Hash. The 128- and 160-bit variants are not
+// part of the ASCON specification, but follow it by encoding
+// the digest size in the initialization vector. This makes
+// each digest size a distinct hash function instead of a
+// truncation of ASCON-Hash.
+func NewHash(digestBits int) (hash.Hash, error) {
+	switch digestBits {
+	case 128, 160, 256:
+	default:
+		return nil, errors.New("ascon: invalid digest size: " + strconv.Itoa(digestBits))
+	}
+	d := &digest{
+		iv:   hashIV(digestBits),
+		size: digestBits / 8,
+	}
+	d.Reset()
+	return d, nil
+}
+
+// HashReader returns the ASCON-Hash digest of the data read
+// from r until EOF.
+//
+// r is read in chunks of up to bufSize bytes, so the data is
+// never held in memory in its entirety. bufSize must be
+// positive.
+func HashReader(r io.Reader, bufSize int) ([HashSize]byte, error) {
+	var sum [HashSize]byte
+	if bufSize <= 0 {
+		return sum, errors.New("ascon: invalid buffer size: " + strconv.Itoa(bufSize))
+	}
+	d := digest{iv: hashIV(256), size: HashSize}
+	d.Reset()
+	buf := make([]byte, bufSize)
+	for {
+		n, err := r.Read(buf)
+		d.absorb(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sum, err
+		}
+	}
+	d.squeeze(sum[:])
+	return sum, nil
+}
+
+// HashShort returns the ASCON-Hash digest of in.
+//
+// It is the same as NewHash(256) followed by Write and Sum, but
+// it does not allocate and starts from a precomputed initial
+// state, which saves one of the permutations. Inputs shorter
+// than HashBlockSize, such as device identifiers, fit in a
+// single padded block and take four permutations instead of
+// five. The remaining four cannot be avoided: one absorbs the
+// block and three separate the 64-bit words of the digest.
+//
+// Longer inputs are hashed correctly, but only save the
+// initial permutation.
+func HashShort(in []byte) [HashSize]byte {
+	var sum [HashSize]byte
+	if len(in) >= HashBlockSize {
+		x := sponge{s: hashInit256}
+		x.absorb(in)
+		x.squeeze(sum[:])
+		return sum
+	}
+	s := hashInit256
+	s.x0 ^= be64n(in) ^ pad(len(in))
+	p12(&s)
+	binary.BigEndian.PutUint64(sum[0:8], s.x0)
+	p12(&s)
+	binary.BigEndian.PutUint64(sum[8:16], s.x0)
+	p12(&s)
+	binary.BigEndian.PutUint64(sum[16:24], s.x0)
+	p12(&s)
+	binary.BigEndian.PutUint64(sum[24:32], s.x0)
+	return sum
+}
+
+// hashInit256 is the initial ASCON-Hash state, that is, the
+// result of sponge.init(hashIV(256)).
+var hashInit256 = state{
+	x0: 0xee9398aadb67f03d,
+	x1: 0x8bb21831c60f1002,
+	x2: 0xb48a92db98d5da62,
+	x3: 0x43189921b8f8e3e8,
+	x4: 0x348fa5c9d525e140,
+}
+
+// digest is an ASCON-Hash digest.
+type digest struct {
+	sponge
+	iv   uint64
+	size int
+}
+
+var (
+	_ hash.Hash       = (*digest)(nil)
+	_ io.StringWriter = (*digest)(nil)
+)
+
+func (d *digest) BlockSize() int {
+	return HashBlockSize
+}
+
+func (d *digest) Size() int {
+	return d.size
+}
+
+func (d *digest) Reset() {
+	d.sponge.init(d.iv)
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	d.absorb(p)
+	return len(p), nil
+}
+
+func (d *digest) WriteString(s string) (int, error) {
+	d.absorbString(s)
+	return len(s), nil
+}
+
+func (d *digest) Sum(b []byte) []byte {
+	// Make a copy so that the caller can keep writing.
+	s := d.sponge
+	ret, out := subtle.SliceForAppend(b, d.size)
+	s.squeeze(out)
+	return ret
+}
+
+// sponge is the ASCON-Hash and ASCON-Xof sponge.
+type sponge struct {
+	s state
+	// buf is the current block.
+	buf [HashBlockSize]byte
+	// n is the number of bytes of buf that have been absorbed
+	// or, once squeezing, squeezed.
+	n int
+	// squeezing is set after the final block has been
+	// absorbed.
+	squeezing bool
+}
+
+// init resets the sponge to its initial state for iv.
+func (x *sponge) init(iv uint64) {
+	*x = sponge{s: state{x0: iv}}
+	p12(&x.s)
+}
+
+// absorb absorbs p.
+//
+// absorb must not be called after squeeze.
+func (x *sponge) absorb(p []byte) {
+	if x.n > 0 {
+		c := copy(x.buf[x.n:], p)
+		x.n += c
+		p = p[c:]
+		if x.n < HashBlockSize {
+			return
+		}
+		x.s.x0 ^= binary.BigEndian.Uint64(x.buf[:])
+		p12(&x.s)
+		x.n = 0
+	}
+	for len(p) >= HashBlockSize {
+		x.s.x0 ^= binary.BigEndian.Uint64(p)
+		p12(&x.s)
+		p = p[HashBlockSize:]
+	}
+	x.n = copy(x.buf[:], p)
+}
+
+// absorbString is like absorb, but for a string.
+//
+// It avoids converting s to a []byte, which would allocate.
+func (x *sponge) absorbString(s string) {
+	var buf [8 * HashBlockSize]byte
+	for len(s) > 0 {
+		n := copy(buf[:], s)
+		x.absorb(buf[:n])
+		s = s[n:]
+	}
+}
+
+// squeeze pads the final block, if it has not already been
+// done, and fills p with output.
+//
+// Successive calls to squeeze continue the same output stream.
+func (x *sponge) squeeze(p []byte) {
+	if !x.squeezing {
+		x.s.x0 ^= be64n(x.buf[:x.n])
+		x.s.x0 ^= pad(x.n)
+		p12(&x.s)
+		binary.BigEndian.PutUint64(x.buf[:], x.s.x0)
+		x.n = 0
+		x.squeezing = true
+	}
+	for len(p) > 0 {
+		if x.n == HashBlockSize {
+			p12(&x.s)
+			binary.BigEndian.PutUint64(x.buf[:], x.s.x0)
+			x.n = 0
+		}
+		c := copy(p, x.buf[x.n:])
+		x.n += c
+		p = p[c:]
+	}
+}
@@ -0,0 +1,174 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// ivHash256 is the Ascon-Hash256 initialization vector from
+// NIST SP 800-232.
+const ivHash256 uint64 = 0x0000080100cc0002
+
+// hash256Init is the Ascon-Hash256 state after the initial
+// permutation of ivHash256.
+//
+// The Ascon-XOF128 and Ascon-CXOF128 equivalents are in
+// xof.go.
+var hash256Init = state{
+	x0: 0x9b1e5494e934d681,
+	x1: 0x4bc3a01e333751d2,
+	x2: 0xae65396c6b34b81a,
+	x3: 0x3c7fd4a4d56a4db3,
+	x4: 0x1a5c464906c5976d,
+}
+
+// NewHash256 creates a hash.Hash that computes Ascon-Hash256
+// digests as standardized in NIST SP 800-232.
+//
+// Ascon-Hash256 is derived from ASCON-Hash, but is not
+// compatible with it: the standard loads the data into the
+// state in little-endian order and uses a different
+// initialization vector and padding. Use NewHash(256) for
+// digests created with the v1.2 submission.
+func NewHash256() hash.Hash {
+	d := &hash256{}
+	d.Reset()
+	return d
+}
+
+// Sum256 returns the Ascon-Hash256 digest of data.
+func Sum256(data []byte) [HashSize]byte {
+	x := spongeLE{s: hash256Init}
+	x.absorb(data)
+	var sum [HashSize]byte
+	x.squeeze(sum[:])
+	return sum
+}
+
+// hash256 is an Ascon-Hash256 digest.
+type hash256 struct {
+	spongeLE
+}
+
+var (
+	_ hash.Hash       = (*hash256)(nil)
+	_ io.StringWriter = (*hash256)(nil)
+)
+
+func (d *hash256) BlockSize() int {
+	return HashBlockSize
+}
+
+func (d *hash256) Size() int {
+	return HashSize
+}
+
+func (d *hash256) Reset() {
+	d.spongeLE = spongeLE{s: hash256Init}
+}
+
+func (d *hash256) Write(p []byte) (int, error) {
+	d.absorb(p)
+	return len(p), nil
+}
+
+func (d *hash256) WriteString(s string) (int, error) {
+	d.absorbString(s)
+	return len(s), nil
+}
+
+func (d *hash256) Sum(b []byte) []byte {
+	// Make a copy so that the caller can keep writing.
+	x := d.spongeLE
+	ret, out := subtle.SliceForAppend(b, HashSize)
+	x.squeeze(out)
+	return ret
+}
+
+// spongeLE is the sponge used by the NIST SP 800-232 hash
+// functions.
+//
+// It is the same as sponge, except that data is loaded into
+// and stored from the state in little-endian order and padded
+// accordingly.
+type spongeLE struct {
+	s state
+	// buf is the current block.
+	buf [HashBlockSize]byte
+	// n is the number of bytes of buf that have been absorbed
+	// or, once squeezing, squeezed.
+	n int
+	// squeezing is set after the final block has been
+	// absorbed.
+	squeezing bool
+}
+
+// absorb absorbs p.
+//
+// absorb must not be called after squeeze.
+func (x *spongeLE) absorb(p []byte) {
+	if x.n > 0 {
+		c := copy(x.buf[x.n:], p)
+		x.n += c
+		p = p[c:]
+		if x.n < HashBlockSize {
+			return
+		}
+		x.s.x0 ^= binary.LittleEndian.Uint64(x.buf[:])
+		p12(&x.s)
+		x.n = 0
+	}
+	for len(p) >= HashBlockSize {
+		x.s.x0 ^= binary.LittleEndian.Uint64(p)
+		p12(&x.s)
+		p = p[HashBlockSize:]
+	}
+	x.n = copy(x.buf[:], p)
+}
+
+// absorbString is like absorb, but for a string.
+//
+// It avoids converting s to a []byte, which would allocate.
+func (x *spongeLE) absorbString(s string) {
+	var buf [8 * HashBlockSize]byte
+	for len(s) > 0 {
+		n := copy(buf[:], s)
+		x.absorb(buf[:n])
+		s = s[n:]
+	}
+}
+
+// pad absorbs the final, padded block.
+//
+// It is separate from squeeze so that Ascon-CXOF128 can pad
+// the customization string.
+func (x *spongeLE) pad() {
+	x.s.x0 ^= le64n(x.buf[:x.n]) ^ padLE(x.n)
+	p12(&x.s)
+	x.n = 0
+}
+
+// squeeze pads the final block, if it has not already been
+// done, and fills p with output.
+//
+// Successive calls to squeeze continue the same output stream.
+func (x *spongeLE) squeeze(p []byte) {
+	if !x.squeezing {
+		x.pad()
+		binary.LittleEndian.PutUint64(x.buf[:], x.s.x0)
+		x.squeezing = true
+	}
+	for len(p) > 0 {
+		if x.n == HashBlockSize {
+			p12(&x.s)
+			binary.LittleEndian.PutUint64(x.buf[:], x.s.x0)
+			x.n = 0
+		}
+		c := copy(p, x.buf[x.n:])
+		x.n += c
+		p = p[c:]
+	}
+}
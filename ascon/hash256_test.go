@@ -0,0 +1,153 @@
+package ascon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHash256Init tests the precomputed initial Ascon-Hash256
+// state.
+func TestHash256Init(t *testing.T) {
+	s := state{x0: ivHash256}
+	p12(&s)
+	if s != hash256Init {
+		t.Fatalf("expected %#x, got %#x", s, hash256Init)
+	}
+}
+
+// TestVectorsHash256 tests NewHash256 and Sum256 against
+// testdata/vectors_hash256.txt. See testdata/README.md for where
+// the vectors come from.
+func TestVectorsHash256(t *testing.T) {
+	vecs := readKAT(t, "vectors_hash256.txt")
+	if len(vecs) != 1025 {
+		t.Fatalf("expected 1025 vectors, got %d", len(vecs))
+	}
+	for i, v := range vecs {
+		h := NewHash256()
+		h.Write(v["Msg"])
+		if got := h.Sum(nil); !bytes.Equal(got, v["MD"]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v["MD"], got)
+		}
+		if got := Sum256(v["Msg"]); !bytes.Equal(got[:], v["MD"]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v["MD"], got)
+		}
+	}
+}
+
+func TestHash256WriteString(t *testing.T) {
+	msg := make([]byte, 600)
+	rand.Read(msg)
+	for n := 0; n <= len(msg); n++ {
+		h1 := NewHash256()
+		h2 := NewHash256()
+		h1.Write(msg[:n])
+		w, err := io.WriteString(h2, string(msg[:n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w != n {
+			t.Fatalf("%d: expected %d, got %d", n, n, w)
+		}
+		want := h1.Sum(nil)
+		if got := h2.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+		}
+	}
+}
+
+// readKAT reads known answer tests in the NIST format from
+// testdata/name: records of "Key = hex" lines separated by
+// blank lines. The Count line is ignored.
+func readKAT(t *testing.T, name string) []map[string][]byte {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var vecs []map[string][]byte
+	v := make(map[string][]byte)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if len(v) > 0 {
+				vecs = append(vecs, v)
+				v = make(map[string][]byte)
+			}
+			continue
+		}
+		i := strings.Index(line, " = ")
+		if i < 0 {
+			t.Fatalf("%s: invalid line: %q", name, line)
+		}
+		if line[:i] == "Count" {
+			continue
+		}
+		b, err := hex.DecodeString(line[i+3:])
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		v[line[:i]] = b
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(v) > 0 {
+		vecs = append(vecs, v)
+	}
+	return vecs
+}
+
+// TestHash256 tests incremental writes, Sum, and Reset.
+func TestHash256(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	msg := make([]byte, 100)
+	rng.Read(msg)
+
+	h := NewHash256()
+	if h.Size() != HashSize || h.BlockSize() != HashBlockSize {
+		t.Fatalf("got size %d, block size %d", h.Size(), h.BlockSize())
+	}
+	for n := 0; n <= len(msg); n++ {
+		want := Sum256(msg[:n])
+
+		// Write in random pieces.
+		h.Reset()
+		for p := msg[:n]; len(p) > 0; {
+			c := rng.Intn(len(p) + 1)
+			h.Write(p[:c])
+			p = p[c:]
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Fatalf("#%d: expected %#x, got %#x", n, want, got)
+		}
+
+		// Sum does not change the state.
+		prefix := []byte("prefix")
+		got := h.Sum(prefix)
+		if !bytes.Equal(got[:len(prefix)], prefix) || !bytes.Equal(got[len(prefix):], want[:]) {
+			t.Fatalf("#%d: expected %#x, got %#x", n, want, got)
+		}
+	}
+
+	// Ascon-Hash256 is not the v1.2 ASCON-Hash.
+	legacy, err := NewHash(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Sum256(nil)
+	if got := legacy.Sum(nil); bytes.Equal(got, want[:]) {
+		t.Fatal("ASCON-Hash matches Ascon-Hash256")
+	}
+}
@@ -0,0 +1,396 @@
+package ascon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/iotest"
+)
+
+// TestHashInit tests the initial ASCON-Hash state against the
+// precomputed values from the reference implementation.
+func TestHashInit(t *testing.T) {
+	var x sponge
+	x.init(hashIV(256))
+	want := state{
+		x0: 0xee9398aadb67f03d,
+		x1: 0x8bb21831c60f1002,
+		x2: 0xb48a92db98d5da62,
+		x3: 0x43189921b8f8e3e8,
+		x4: 0x348fa5c9d525e140,
+	}
+	if x.s != want {
+		t.Fatalf("expected %#x, got %#x", want, x.s)
+	}
+	if hashInit256 != want {
+		t.Fatalf("expected %#x, got %#x", want, hashInit256)
+	}
+}
+
+func TestHashVectors(t *testing.T) {
+	for i, tc := range []struct {
+		msg string
+		md  string
+	}{
+		{"", "7346bc14f036e87ae03d0997913088f5f68411434b3cf8b54fa796a80d251f91"},
+		{"00", "8dd446ada58a7740ecf56eb638ef775f7d5c0fd5f0c2bbbdfdec29609d3c43a2"},
+	} {
+		msg, _ := hex.DecodeString(tc.msg)
+		want, _ := hex.DecodeString(tc.md)
+		h, err := NewHash(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Write(msg)
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	msg := make([]byte, 100)
+	rng.Read(msg)
+
+	sums := make(map[int][]byte)
+	for _, bits := range []int{128, 160, 256} {
+		h, err := NewHash(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Size() != bits/8 {
+			t.Fatalf("%d: expected %d, got %d", bits, bits/8, h.Size())
+		}
+		h.Write(msg)
+		want := h.Sum(nil)
+		if len(want) != bits/8 {
+			t.Fatalf("%d: expected %d bytes, got %d", bits, bits/8, len(want))
+		}
+		sums[bits] = want
+
+		// Sum does not change the state.
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("%d: expected %#x, got %#x", bits, want, got)
+		}
+
+		// Writes are split arbitrarily.
+		for n := 0; n <= len(msg); n++ {
+			h.Reset()
+			h.Write(msg[:n])
+			for _, p := range split(rng, msg[n:]) {
+				h.Write(p)
+			}
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("%d: #%d: expected %#x, got %#x", bits, n, want, got)
+			}
+		}
+	}
+
+	// Each digest size is a distinct function, not a truncation.
+	if bytes.HasPrefix(sums[256], sums[128]) ||
+		bytes.HasPrefix(sums[256], sums[160]) ||
+		bytes.HasPrefix(sums[160], sums[128]) {
+		t.Fatal("digest is a truncation of a longer digest")
+	}
+
+	for _, bits := range []int{-1, 0, 64, 192, 512} {
+		if _, err := NewHash(bits); err == nil {
+			t.Fatalf("%d: expected an error", bits)
+		}
+	}
+}
+
+// TestHashShort tests that HashShort matches NewHash(256),
+// exhaustively for one-byte inputs and with random inputs of
+// every length up to a few blocks.
+func TestHashShort(t *testing.T) {
+	hash := func(in []byte) []byte {
+		h, err := NewHash(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Write(in)
+		return h.Sum(nil)
+	}
+
+	for i := 0; i < 256; i++ {
+		in := []byte{byte(i)}
+		want := hash(in)
+		if got := HashShort(in); !bytes.Equal(got[:], want) {
+			t.Fatalf("%#x: expected %#x, got %#x", in, want, got)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for n := 0; n <= 4*HashBlockSize; n++ {
+		for i := 0; i < 100; i++ {
+			in := make([]byte, n)
+			rng.Read(in)
+			want := hash(in)
+			if got := HashShort(in); !bytes.Equal(got[:], want) {
+				t.Fatalf("%#x: expected %#x, got %#x", in, want, got)
+			}
+		}
+	}
+
+	if n := testing.AllocsPerRun(100, func() {
+		HashShort([]byte{1, 2, 3, 4})
+	}); n != 0 {
+		t.Fatalf("expected zero allocations, got %.1f", n)
+	}
+}
+
+func TestHashWriteString(t *testing.T) {
+	msg := make([]byte, 200)
+	rand.Read(msg)
+	for n := 0; n <= len(msg); n++ {
+		h1, _ := NewHash(256)
+		h2, _ := NewHash(256)
+		h1.Write(msg[:n])
+		w, err := io.WriteString(h2, string(msg[:n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w != n {
+			t.Fatalf("%d: expected %d, got %d", n, n, w)
+		}
+		want := h1.Sum(nil)
+		if got := h2.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+		}
+	}
+}
+
+func TestHashReader(t *testing.T) {
+	data := make([]byte, 1000)
+	rand.Read(data)
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 1, 7, 8, 9, 100, 999, 1000} {
+		h, err := NewHash(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Write(data[:n])
+		want := h.Sum(nil)
+
+		for _, bufSize := range []int{1, 3, 8, 64, 4096} {
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := HashReader(io.LimitReader(f, int64(n)), bufSize)
+			f.Close()
+			if err != nil {
+				t.Fatalf("(%d, %d): %v", n, bufSize, err)
+			}
+			if !bytes.Equal(got[:], want) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x", n, bufSize, want, got)
+			}
+
+			// Short reads and data returned with io.EOF.
+			for _, r := range []io.Reader{
+				iotest.OneByteReader(bytes.NewReader(data[:n])),
+				iotest.HalfReader(bytes.NewReader(data[:n])),
+				iotest.DataErrReader(bytes.NewReader(data[:n])),
+			} {
+				got, err := HashReader(r, bufSize)
+				if err != nil {
+					t.Fatalf("(%d, %d): %v", n, bufSize, err)
+				}
+				if !bytes.Equal(got[:], want) {
+					t.Fatalf("(%d, %d): expected %#x, got %#x", n, bufSize, want, got)
+				}
+			}
+		}
+	}
+
+	errTest := errors.New("test")
+	r := io.MultiReader(bytes.NewReader(data), iotest.ErrReader(errTest))
+	if _, err := HashReader(r, 64); err != errTest {
+		t.Fatalf("expected %v, got %v", errTest, err)
+	}
+	for _, bufSize := range []int{-1, 0} {
+		if _, err := HashReader(bytes.NewReader(data), bufSize); err == nil {
+			t.Fatalf("%d: expected an error", bufSize)
+		}
+	}
+}
+
+// split splits p into randomly sized pieces.
+func split(rng *rand.Rand, p []byte) [][]byte {
+	var pieces [][]byte
+	for len(p) > 0 {
+		n := rng.Intn(len(p)) + 1
+		pieces = append(pieces, p[:n])
+		p = p[n:]
+	}
+	return pieces
+}
+
+// TestState tests that State with 12 rounds computes
+// ASCON-Hash.
+func TestState(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for n := 0; n < 3*HashBlockSize+2; n++ {
+		msg := make([]byte, n)
+		rng.Read(msg)
+
+		// Pad the final block.
+		padded := append(msg[:n:n], 0x80)
+		for len(padded)%HashBlockSize != 0 {
+			padded = append(padded, 0)
+		}
+
+		s := NewHashState()
+		for len(padded) > 0 {
+			var b [HashBlockSize]byte
+			copy(b[:], padded)
+			s.AbsorbBlock(b, 12)
+			padded = padded[HashBlockSize:]
+		}
+		var got []byte
+		for len(got) < HashSize {
+			b := s.SqueezeBlock(12)
+			got = append(got, b[:]...)
+		}
+
+		h, err := NewHash(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Write(msg)
+		want := h.Sum(nil)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+		}
+	}
+}
+
+func TestStateRounds(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for rounds := 1; rounds <= 12; rounds++ {
+		s := randState(rng)
+		want, got := s, s
+		for r := 12 - rounds; r < 12; r++ {
+			roundGeneric(&want, uint64((0xf-r)<<4|r))
+		}
+		permute(&got, rounds)
+		if want != got {
+			t.Fatalf("%d: expected %v, got %v", rounds, want, got)
+		}
+	}
+
+	s := NewHashState()
+	for _, rounds := range []int{-1, 0, 13} {
+		msg := "ascon: invalid number of rounds: " + strconv.Itoa(rounds)
+		mustPanic(t, msg, func() {
+			s.AbsorbBlock([HashBlockSize]byte{}, rounds)
+		})
+		mustPanic(t, msg, func() {
+			s.SqueezeBlock(rounds)
+		})
+	}
+}
+
+func TestPermuteBatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for _, rounds := range []int{1, 6, 8, 11, 12} {
+		states := make([][5]uint64, 37)
+		want := make([][5]uint64, len(states))
+		for i := range states {
+			s := randState(rng)
+			states[i] = [5]uint64{s.x0, s.x1, s.x2, s.x3, s.x4}
+
+			for r := 12 - rounds; r < 12; r++ {
+				roundGeneric(&s, uint64((0xf-r)<<4|r))
+			}
+			want[i] = [5]uint64{s.x0, s.x1, s.x2, s.x3, s.x4}
+
+			got := states[i]
+			Permute(&got, rounds)
+			if got != want[i] {
+				t.Fatalf("(%d, #%d): expected %#x, got %#x", rounds, i, want[i], got)
+			}
+		}
+		PermuteBatch(states, rounds)
+		for i := range states {
+			if states[i] != want[i] {
+				t.Fatalf("(%d, #%d): expected %#x, got %#x", rounds, i, want[i], states[i])
+			}
+		}
+	}
+
+	mustPanic(t, "ascon: invalid number of rounds: 0", func() {
+		PermuteBatch(nil, 0)
+	})
+	mustPanic(t, "ascon: invalid number of rounds: 13", func() {
+		Permute(&[5]uint64{}, 13)
+	})
+}
+
+func BenchmarkPermute(b *testing.B) {
+	states := make([][5]uint64, 64)
+	b.SetBytes(int64(len(states) * 40))
+	for i := 0; i < b.N; i++ {
+		for j := range states {
+			Permute(&states[j], 12)
+		}
+	}
+}
+
+func BenchmarkPermuteBatch(b *testing.B) {
+	states := make([][5]uint64, 64)
+	b.SetBytes(int64(len(states) * 40))
+	for i := 0; i < b.N; i++ {
+		PermuteBatch(states, 12)
+	}
+}
+
+func BenchmarkHash7(b *testing.B) {
+	benchmarkHash(b, 7)
+}
+
+func BenchmarkHash8(b *testing.B) {
+	benchmarkHash(b, 8)
+}
+
+func benchmarkHash(b *testing.B, n int) {
+	in := make([]byte, n)
+	out := make([]byte, 0, HashSize)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		h, err := NewHash(256)
+		if err != nil {
+			b.Fatal(err)
+		}
+		h.Write(in)
+		out = h.Sum(out[:0])
+	}
+}
+
+func BenchmarkHashShort7(b *testing.B) {
+	benchmarkHashShort(b, 7)
+}
+
+func BenchmarkHashShort8(b *testing.B) {
+	benchmarkHashShort(b, 8)
+}
+
+func benchmarkHashShort(b *testing.B, n int) {
+	in := make([]byte, n)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		HashShort(in)
+	}
+}
@@ -0,0 +1,7 @@
+#define CRYPTO_VERSION "1.2.5"
+#define CRYPTO_KEYBYTES 20
+#define CRYPTO_NSECBYTES 0
+#define CRYPTO_NPUBBYTES 16
+#define CRYPTO_ABYTES 16
+#define CRYPTO_NOOVERLAP 1
+#define ASCON_AEAD_RATE 8
@@ -0,0 +1,11 @@
+int crypto_aead_encrypt_80pq(unsigned char *c, unsigned long long *clen,
+			     const unsigned char *m, unsigned long long mlen,
+			     const unsigned char *ad, unsigned long long adlen,
+			     const unsigned char *nsec, const unsigned char *npub,
+			     const unsigned char *k);
+
+int crypto_aead_decrypt_80pq(unsigned char *m, unsigned long long *mlen,
+			     unsigned char *nsec, const unsigned char *c,
+			     unsigned long long clen, const unsigned char *ad,
+			     unsigned long long adlen, const unsigned char *npub,
+			     const unsigned char *k);
@@ -0,0 +1,101 @@
+//go:build interop
+// +build interop
+
+package ascon
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"testing"
+)
+
+// TestInterop compares Seal with testdata/ascon.py, an
+// independent implementation written in Python, to catch bugs
+// that this package and the C reference might share.
+//
+// It requires python3 in PATH and only runs with the interop
+// build tag:
+//
+//	go test -tags interop -run TestInterop ./ascon
+func TestInterop(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Fatalf("python3 is required: %v", err)
+	}
+
+	type testCase struct {
+		variant   string
+		aead      cipher.AEAD
+		key       []byte
+		nonce     []byte
+		plaintext []byte
+		ad        []byte
+	}
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	var cases []testCase
+	for _, v := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+		{"aead128", NewAEAD128},
+	} {
+		for ptLen := 0; ptLen <= 3*BlockSize128a+1; ptLen++ {
+			for _, adLen := range []int{0, 1, 7, 8, 15, 16, 17, 40} {
+				key := make([]byte, KeySize)
+				nonce := make([]byte, NonceSize)
+				plaintext := make([]byte, ptLen)
+				ad := make([]byte, adLen)
+				rng.Read(key)
+				rng.Read(nonce)
+				rng.Read(plaintext)
+				rng.Read(ad)
+				aead, err := v.fn(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cases = append(cases, testCase{v.name, aead, key, nonce, plaintext, ad})
+			}
+		}
+	}
+
+	var stdin bytes.Buffer
+	for _, tc := range cases {
+		fmt.Fprintf(&stdin, "%s,%x,%x,%x,%x\n",
+			tc.variant, tc.key, tc.nonce, tc.plaintext, tc.ad)
+	}
+	cmd := exec.Command(python, "testdata/ascon.py")
+	cmd.Stdin = &stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("ascon.py: %v\n%s", err, stderr.Bytes())
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for i, tc := range cases {
+		if !sc.Scan() {
+			t.Fatalf("ascon.py: missing output for #%d", i)
+		}
+		want, err := hex.DecodeString(sc.Text())
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		got := tc.aead.Seal(nil, tc.nonce, tc.plaintext, tc.ad)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("#%d (%s, %d, %d): expected %#x, got %#x",
+				i, tc.variant, len(tc.plaintext), len(tc.ad), want, got)
+		}
+	}
+	if sc.Scan() {
+		t.Fatalf("ascon.py: unexpected output: %q", sc.Text())
+	}
+	t.Logf("checked %d vectors", len(cases))
+}
@@ -0,0 +1,40 @@
+package ascon
+
+import "github.com/ericlagergren/lwcrypto/internal/kat"
+
+// KATEntry is a known answer test vector.
+type KATEntry struct {
+	// Count is the one-based index of the vector.
+	Count int
+	Key   []byte
+	Nonce []byte
+	PT    []byte
+	AD    []byte
+	// CT is the ciphertext followed by the tag.
+	CT []byte
+}
+
+// GenerateKAT deterministically generates count ASCON-128
+// known answer test vectors.
+//
+// The vectors follow the convention of genkat_aead.c from the
+// NIST Lightweight Cryptography project: the key, nonce,
+// plaintext, and additional data are the bytes 0, 1, 2, and so
+// on, and vector i has (i-1)/33 bytes of plaintext and
+// (i-1)%33 bytes of additional data. The first 1089 vectors are
+// the official known answer tests. Further vectors continue the
+// same pattern with longer plaintexts.
+func GenerateKAT(count int) []KATEntry {
+	if count <= 0 {
+		return nil
+	}
+	a, err := New128(kat.Incrementing(KeySize))
+	if err != nil {
+		panic(err)
+	}
+	vecs := make([]KATEntry, count)
+	for i, v := range kat.Generate(a, KeySize, count) {
+		vecs[i] = KATEntry(v)
+	}
+	return vecs
+}
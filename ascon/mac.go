@@ -0,0 +1,277 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/ericlagergren/subtle"
+)
+
+const (
+	// MACSize is the size in bytes of an ASCON-Mac tag.
+	MACSize = 16
+	// MACBlockSize is the rate in bytes at which ASCON-Mac
+	// absorbs its input.
+	MACBlockSize = 32
+)
+
+// Initialization vectors for ASCON-Mac and ASCON-Prf.
+//
+// They encode the key size, the output rate, the number of
+// rounds, and the output size in bits, which is zero for the
+// arbitrary-length output of ASCON-Prf.
+const (
+	ivMAC uint64 = 0x80808c0000000080
+	ivPRF uint64 = 0x80808c0000000000
+)
+
+// MAC is an ASCON-Mac message authentication code.
+//
+// The hash.Hash returned by NewMAC is a *MAC. Use a type
+// assertion to access Verify.
+type MAC struct {
+	x prfSponge
+}
+
+var (
+	_ hash.Hash       = (*MAC)(nil)
+	_ io.StringWriter = (*MAC)(nil)
+)
+
+// NewMAC creates an ASCON-Mac with a 128-bit key.
+//
+// ASCON-Mac is the keyed PRF-based MAC from the ASCON family. It
+// absorbs 32 bytes per permutation, so authenticating data is
+// faster than sealing it with an empty plaintext.
+func NewMAC(key []byte) (hash.Hash, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("ascon: bad key length")
+	}
+	m := &MAC{}
+	m.x.init(ivMAC, key)
+	return m, nil
+}
+
+func (m *MAC) BlockSize() int {
+	return MACBlockSize
+}
+
+func (m *MAC) Size() int {
+	return MACSize
+}
+
+func (m *MAC) Reset() {
+	m.x.reset()
+}
+
+func (m *MAC) Write(p []byte) (int, error) {
+	m.x.absorb(p)
+	return len(p), nil
+}
+
+func (m *MAC) WriteString(s string) (int, error) {
+	m.x.absorbString(s)
+	return len(s), nil
+}
+
+func (m *MAC) Sum(b []byte) []byte {
+	tag := m.tag()
+	return append(b, tag[:]...)
+}
+
+// Verify reports whether tag is the ASCON-Mac tag of the data
+// written so far.
+//
+// The comparison is constant time. Like Sum, Verify does not
+// change the state.
+func (m *MAC) Verify(tag []byte) bool {
+	want := m.tag()
+	return subtle.ConstantTimeCompare(want[:], tag) == 1
+}
+
+// tag returns the tag of the data written so far without
+// changing the state.
+func (m *MAC) tag() [MACSize]byte {
+	x := m.x
+	x.final()
+	var tag [MACSize]byte
+	binary.BigEndian.PutUint64(tag[0:8], x.s.x0)
+	binary.BigEndian.PutUint64(tag[8:16], x.s.x1)
+	return tag
+}
+
+// NewPRF creates an ASCON-Prf with a 128-bit key.
+//
+// ASCON-Prf is a keyed XOF: Write absorbs input and Read
+// squeezes any amount of output, 16 bytes per permutation.
+// Successive calls to Read continue the same output stream, so
+// the output does not depend on how it is read. The first 16
+// bytes of output are not the same as the ASCON-Mac tag, since
+// the two use different initialization vectors.
+//
+// Write panics if it is called after Read. Reset keeps the key.
+func NewPRF(key []byte) (XOF, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("ascon: bad key length")
+	}
+	p := &prf{}
+	p.x.init(ivPRF, key)
+	return p, nil
+}
+
+// prf is ASCON-Prf.
+type prf struct {
+	x prfSponge
+	// out is the current output block.
+	out [16]byte
+	// off is the number of bytes of out that have been read.
+	off int
+	// squeezing is set by the first call to Read.
+	squeezing bool
+}
+
+var (
+	_ XOF             = (*prf)(nil)
+	_ io.StringWriter = (*prf)(nil)
+)
+
+func (p *prf) Write(b []byte) (int, error) {
+	if p.squeezing {
+		panic("ascon: Write after Read")
+	}
+	p.x.absorb(b)
+	return len(b), nil
+}
+
+func (p *prf) WriteString(s string) (int, error) {
+	if p.squeezing {
+		panic("ascon: Write after Read")
+	}
+	p.x.absorbString(s)
+	return len(s), nil
+}
+
+func (p *prf) Read(b []byte) (int, error) {
+	n := len(b)
+	if !p.squeezing {
+		p.x.final()
+		p.store()
+		p.squeezing = true
+	}
+	for len(b) > 0 {
+		if p.off == len(p.out) {
+			p12(&p.x.s)
+			p.store()
+		}
+		c := copy(b, p.out[p.off:])
+		p.off += c
+		b = b[c:]
+	}
+	return n, nil
+}
+
+// store stores the output rate of the state in out.
+func (p *prf) store() {
+	binary.BigEndian.PutUint64(p.out[0:8], p.x.s.x0)
+	binary.BigEndian.PutUint64(p.out[8:16], p.x.s.x1)
+	p.off = 0
+}
+
+func (p *prf) Reset() {
+	p.x.reset()
+	p.out = [16]byte{}
+	p.off = 0
+	p.squeezing = false
+}
+
+func (p *prf) Clone() XOF {
+	p0 := *p
+	return &p0
+}
+
+// prfSponge absorbs the input of ASCON-Mac and ASCON-Prf.
+type prfSponge struct {
+	// initial is the state after the key has been absorbed.
+	initial state
+	s       state
+	// buf is the current partial block.
+	buf [MACBlockSize]byte
+	// n is the number of bytes in buf.
+	n int
+}
+
+// init initializes the sponge with iv and key, which must be
+// KeySize bytes.
+func (x *prfSponge) init(iv uint64, key []byte) {
+	x.initial = state{
+		x0: iv,
+		x1: binary.BigEndian.Uint64(key[0:8]),
+		x2: binary.BigEndian.Uint64(key[8:16]),
+	}
+	p12(&x.initial)
+	x.reset()
+}
+
+// reset discards any input.
+func (x *prfSponge) reset() {
+	x.s = x.initial
+	x.buf = [MACBlockSize]byte{}
+	x.n = 0
+}
+
+// absorb absorbs p.
+func (x *prfSponge) absorb(p []byte) {
+	if x.n > 0 {
+		c := copy(x.buf[x.n:], p)
+		x.n += c
+		p = p[c:]
+		if x.n < MACBlockSize {
+			return
+		}
+		x.block(x.buf[:])
+		x.n = 0
+	}
+	for len(p) >= MACBlockSize {
+		x.block(p)
+		p = p[MACBlockSize:]
+	}
+	x.n = copy(x.buf[:], p)
+}
+
+// block absorbs a full block.
+// absorbString is like absorb, but for a string.
+//
+// It avoids converting s to a []byte, which would allocate.
+func (x *prfSponge) absorbString(s string) {
+	var buf [8 * MACBlockSize]byte
+	for len(s) > 0 {
+		n := copy(buf[:], s)
+		x.absorb(buf[:n])
+		s = s[n:]
+	}
+}
+
+func (x *prfSponge) block(p []byte) {
+	x.s.x0 ^= binary.BigEndian.Uint64(p[0:8])
+	x.s.x1 ^= binary.BigEndian.Uint64(p[8:16])
+	x.s.x2 ^= binary.BigEndian.Uint64(p[16:24])
+	x.s.x3 ^= binary.BigEndian.Uint64(p[24:32])
+	p12(&x.s)
+}
+
+// final absorbs the final, padded block, separates the domain,
+// and permutes the state, after which the first 16 bytes of
+// output are in x0 and x1.
+func (x *prfSponge) final() {
+	var block [MACBlockSize]byte
+	copy(block[:], x.buf[:x.n])
+	block[x.n] = 0x80
+	x.s.x0 ^= binary.BigEndian.Uint64(block[0:8])
+	x.s.x1 ^= binary.BigEndian.Uint64(block[8:16])
+	x.s.x2 ^= binary.BigEndian.Uint64(block[16:24])
+	x.s.x3 ^= binary.BigEndian.Uint64(block[24:32])
+	x.s.x4 ^= 1
+	p12(&x.s)
+}
@@ -0,0 +1,253 @@
+package ascon
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// TestVectorsMAC tests NewMAC against testdata/vectors_mac.txt.
+// See testdata/README.md for where the vectors come from.
+func TestVectorsMAC(t *testing.T) {
+	vecs := readKAT(t, "vectors_mac.txt")
+	if len(vecs) != 1025 {
+		t.Fatalf("expected 1025 vectors, got %d", len(vecs))
+	}
+	for i, v := range vecs {
+		h, err := NewMAC(v["Key"])
+		if err != nil {
+			t.Fatalf("#%d: %v", i+1, err)
+		}
+		h.Write(v["Msg"])
+		if got := h.Sum(nil); !bytes.Equal(got, v["Tag"]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v["Tag"], got)
+		}
+		if !h.(*MAC).Verify(v["Tag"]) {
+			t.Fatalf("#%d: Verify failed", i+1)
+		}
+	}
+}
+
+func TestMAC(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	rng.Read(key)
+	msg := make([]byte, 200)
+	rng.Read(msg)
+
+	h, err := NewMAC(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Size() != MACSize || h.BlockSize() != MACBlockSize {
+		t.Fatalf("got size %d, block size %d", h.Size(), h.BlockSize())
+	}
+	m := h.(*MAC)
+	for n := 0; n <= len(msg); n++ {
+		h.Reset()
+		h.Write(msg[:n])
+		want := h.Sum(nil)
+
+		// Write in random pieces.
+		h.Reset()
+		for p := msg[:n]; len(p) > 0; {
+			c := rng.Intn(len(p) + 1)
+			h.Write(p[:c])
+			p = p[c:]
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("#%d: expected %#x, got %#x", n, want, got)
+		}
+		// Sum does not change the state.
+		if got := h.Sum([]byte("prefix")); !bytes.Equal(got[6:], want) {
+			t.Fatalf("#%d: expected %#x, got %#x", n, want, got[6:])
+		}
+
+		if !m.Verify(want) {
+			t.Fatalf("#%d: Verify failed", n)
+		}
+		bad := append([]byte(nil), want...)
+		bad[rng.Intn(len(bad))] ^= 1 << uint(rng.Intn(8))
+		if m.Verify(bad) {
+			t.Fatalf("#%d: Verify accepted a bad tag", n)
+		}
+		if m.Verify(want[:MACSize-1]) {
+			t.Fatalf("#%d: Verify accepted a short tag", n)
+		}
+	}
+
+	// The tag depends on the key.
+	h.Reset()
+	h.Write(msg)
+	want := h.Sum(nil)
+	key[0] ^= 1
+	h2, err := NewMAC(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2.Write(msg)
+	if got := h2.Sum(nil); bytes.Equal(got, want) {
+		t.Fatal("different keys produced the same tag")
+	}
+
+	if _, err := NewMAC(key[:KeySize-1]); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+// BenchmarkMAC4K is comparable to BenchmarkAuthenticate4K.
+func BenchmarkMAC4K(b *testing.B) {
+	key := make([]byte, KeySize)
+	msg := make([]byte, 4*1024)
+	h, err := NewMAC(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tag := make([]byte, 0, MACSize)
+	b.SetBytes(int64(len(msg)))
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(msg)
+		tag = h.Sum(tag[:0])
+	}
+}
+
+func TestMACWriteString(t *testing.T) {
+	key := make([]byte, KeySize)
+	msg := make([]byte, 600)
+	rand.Read(key)
+	rand.Read(msg)
+	for n := 0; n <= len(msg); n++ {
+		h1, err := NewMAC(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h2, err := NewMAC(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h1.Write(msg[:n])
+		w, err := io.WriteString(h2, string(msg[:n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w != n {
+			t.Fatalf("%d: expected %d, got %d", n, n, w)
+		}
+		want := h1.Sum(nil)
+		if got := h2.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+		}
+	}
+}
+
+// TestVectorsPRF tests NewPRF against testdata/vectors_prf.txt,
+// which has outputs of one or more blocks. See
+// testdata/README.md for where the vectors come from.
+func TestVectorsPRF(t *testing.T) {
+	for i, v := range readKAT(t, "vectors_prf.txt") {
+		x, err := NewPRF(v["Key"])
+		if err != nil {
+			t.Fatalf("#%d: %v", i+1, err)
+		}
+		x.Write(v["Msg"])
+		got := make([]byte, len(v["Out"]))
+		x.Read(got)
+		if !bytes.Equal(got, v["Out"]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v["Out"], got)
+		}
+	}
+}
+
+func TestPRF(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	rng.Read(key)
+	msg := make([]byte, 100)
+	rng.Read(msg)
+
+	x, err := NewPRF(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Write(msg)
+	want := make([]byte, 1000)
+	x.Read(want)
+
+	// The output stream does not depend on how it is read.
+	for i := 0; i < 10; i++ {
+		x.Reset()
+		for p := msg; len(p) > 0; {
+			n := rng.Intn(len(p) + 1)
+			x.Write(p[:n])
+			p = p[n:]
+		}
+		y := x.Clone()
+		got := make([]byte, len(want))
+		for p := got; len(p) > 0; {
+			n := rng.Intn(len(p) + 1)
+			x.Read(p[:n])
+			p = p[n:]
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+		}
+		y.Read(got)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+		}
+	}
+
+	mustPanic(t, "ascon: Write after Read", func() {
+		x.Write(msg)
+	})
+
+	// The PRF and the MAC are distinct.
+	h, err := NewMAC(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write(msg)
+	if got := h.Sum(nil); bytes.Equal(got, want[:MACSize]) {
+		t.Fatal("ASCON-Prf output matches the ASCON-Mac tag")
+	}
+
+	if _, err := NewPRF(key[:KeySize-1]); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestPRFWriteString(t *testing.T) {
+	key := make([]byte, KeySize)
+	msg := make([]byte, 600)
+	rand.Read(key)
+	rand.Read(msg)
+	x, err := NewPRF(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n <= len(msg); n++ {
+		x1 := x.Clone()
+		x2 := x.Clone()
+		x1.Write(msg[:n])
+		w, err := io.WriteString(x2, string(msg[:n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w != n {
+			t.Fatalf("%d: expected %d, got %d", n, n, w)
+		}
+		want := make([]byte, 40)
+		got := make([]byte, 40)
+		x1.Read(want)
+		x2.Read(got)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+		}
+	}
+	mustPanic(t, "ascon: Write after Read", func() {
+		x.Read(make([]byte, 1))
+		io.WriteString(x, "x")
+	})
+}
@@ -0,0 +1,89 @@
+package ascon
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// marshalVersion is the version of the key blob format.
+	marshalVersion = 1
+	// marshalSize is the size in bytes of a key blob.
+	marshalSize = 2 + KeySize
+)
+
+// Algorithm identifiers in key blobs.
+//
+// They are distinct from those used by package grain.
+const (
+	algASCON128  = 1
+	algASCON128a = 2
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*AEAD)(nil)
+	_ encoding.BinaryUnmarshaler = (*AEAD)(nil)
+)
+
+// MarshalBinary encodes the key as
+//
+//	version || algorithm || key
+//
+// where version and algorithm are one byte each. algorithm is 1
+// for ASCON-128 and 2 for ASCON-128a.
+//
+// The result contains the secret key and must be protected
+// accordingly. Counters such as those returned by Stats are not
+// included.
+//
+// MarshalBinary returns an error for AEADs created by
+// NewWithDomain, NewWithLengthBlock, or New80pq, since the
+// format cannot describe them.
+func (a *AEAD) MarshalBinary() ([]byte, error) {
+	if a.destroyed {
+		panic("ascon: use after Destroy")
+	}
+	if a.domain != 0 || a.lengthBlock {
+		return nil, errors.New("ascon: cannot marshal non-standard AEAD")
+	}
+	if a.iv == iv80pq {
+		return nil, errors.New("ascon: cannot marshal ASCON-80pq AEAD")
+	}
+	alg := byte(algASCON128)
+	if a.iv == iv128a {
+		alg = algASCON128a
+	}
+	b := make([]byte, marshalSize)
+	b[0] = marshalVersion
+	b[1] = alg
+	binary.BigEndian.PutUint64(b[2:10], a.k0)
+	binary.BigEndian.PutUint64(b[10:18], a.k1)
+	return b, nil
+}
+
+// UnmarshalBinary replaces a with the AEAD encoded by
+// MarshalBinary.
+func (a *AEAD) UnmarshalBinary(data []byte) error {
+	if len(data) != marshalSize {
+		return errors.New("ascon: invalid key blob length")
+	}
+	if data[0] != marshalVersion {
+		return errors.New("ascon: unsupported key blob version")
+	}
+	var iv uint64
+	switch data[1] {
+	case algASCON128:
+		iv = iv128
+	case algASCON128a:
+		iv = iv128a
+	default:
+		return errors.New("ascon: unknown key blob algorithm")
+	}
+	b, err := newAEAD(data[2:], iv)
+	if err != nil {
+		return err
+	}
+	*a = *b
+	return nil
+}
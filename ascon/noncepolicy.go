@@ -0,0 +1,60 @@
+package ascon
+
+import "errors"
+
+// NoncePolicyAEAD is an ASCON-128 AEAD that checks each nonce
+// against a caller-provided policy before sealing with it.
+//
+// It is intended for deployments that must enforce how nonces
+// are constructed, such as requiring a fixed prefix followed by
+// a counter, or rejecting nonces that are all zeros.
+type NoncePolicyAEAD struct {
+	aead   AEAD
+	policy func(nonce []byte) error
+}
+
+// NewWithNoncePolicy creates an ASCON-128 AEAD that calls policy
+// with each nonce passed to Seal.
+//
+// If policy returns an error, Seal returns that error without
+// encrypting anything. policy must not modify or retain the
+// nonce.
+func NewWithNoncePolicy(key []byte, policy func(nonce []byte) error) (*NoncePolicyAEAD, error) {
+	if policy == nil {
+		return nil, errors.New("ascon: nil nonce policy")
+	}
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	return &NoncePolicyAEAD{aead: *a, policy: policy}, nil
+}
+
+// NonceSize returns the size of the nonce that must be passed
+// to Seal and Open.
+func (p *NoncePolicyAEAD) NonceSize() int {
+	return NonceSize
+}
+
+// Overhead returns the maximum difference between the lengths
+// of a plaintext and its ciphertext.
+func (p *NoncePolicyAEAD) Overhead() int {
+	return TagSize
+}
+
+// Seal is like cipher.AEAD.Seal, but first checks nonce against
+// the policy and returns the policy's error, if any, without
+// encrypting anything.
+func (p *NoncePolicyAEAD) Seal(dst, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	checkNonce(nonce)
+	if err := p.policy(nonce); err != nil {
+		return nil, err
+	}
+	return p.aead.Seal(dst, nonce, plaintext, additionalData), nil
+}
+
+// Open is the same as cipher.AEAD.Open. It does not check the
+// nonce against the policy.
+func (p *NoncePolicyAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	return p.aead.Open(dst, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,106 @@
+package ascon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestNoncePolicy(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 1
+
+	errZeroNonce := errors.New("nonce is all zeros")
+	nonZero := func(nonce []byte) error {
+		for _, v := range nonce {
+			if v != 0 {
+				return nil
+			}
+		}
+		return errZeroNonce
+	}
+	p, err := NewWithNoncePolicy(key, nonZero)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zero := make([]byte, NonceSize)
+	if _, err := p.Seal(nil, zero, []byte("hello"), nil); err != errZeroNonce {
+		t.Fatalf("expected %v, got %v", errZeroNonce, err)
+	}
+
+	nonce := make([]byte, NonceSize)
+	nonce[NonceSize-1] = 1
+	ciphertext, err := p.Seal(nil, nonce, []byte("hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := c.Seal(nil, nonce, []byte("hello"), nil); !bytes.Equal(ciphertext, want) {
+		t.Fatalf("expected %#x, got %#x", want, ciphertext)
+	}
+	plaintext, err := p.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "hello" {
+		t.Fatalf("expected %q, got %q", "hello", plaintext)
+	}
+
+	if _, err := NewWithNoncePolicy(key, nil); err == nil {
+		t.Fatal("expected an error for a nil policy")
+	}
+}
+
+func TestNoncePolicyPrefixCounter(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 1
+
+	// Nonces must be an 8-byte prefix followed by a
+	// strictly increasing 64-bit counter.
+	prefix := []byte("deviceID")
+	errPrefix := errors.New("wrong nonce prefix")
+	errCounter := errors.New("nonce counter did not increase")
+	var last uint64
+	policy := func(nonce []byte) error {
+		if !bytes.Equal(nonce[:8], prefix) {
+			return errPrefix
+		}
+		ctr := binary.BigEndian.Uint64(nonce[8:])
+		if ctr <= last {
+			return errCounter
+		}
+		last = ctr
+		return nil
+	}
+	p, err := NewWithNoncePolicy(key, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := func(prefix string, ctr uint64) []byte {
+		n := make([]byte, NonceSize)
+		copy(n, prefix)
+		binary.BigEndian.PutUint64(n[8:], ctr)
+		return n
+	}
+	for i, tc := range []struct {
+		nonce []byte
+		err   error
+	}{
+		{nonce("deviceID", 1), nil},
+		{nonce("deviceID", 2), nil},
+		{nonce("deviceID", 2), errCounter},
+		{nonce("otherDev", 3), errPrefix},
+		{nonce("deviceID", 10), nil},
+		{nonce("deviceID", 9), errCounter},
+	} {
+		if _, err := p.Seal(nil, tc.nonce, nil, nil); err != tc.err {
+			t.Fatalf("#%d: expected %v, got %v", i, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !purego && !faultinject
+// +build !purego,!faultinject
+
+package ascon
+
+const purego = false
@@ -0,0 +1,54 @@
+//go:build aeaddebug
+// +build aeaddebug
+
+package ascon
+
+// OpenTagDiagnostic is like Open, but when authentication fails
+// it also reports how many leading bytes of the tag in
+// ciphertext match the expected tag.
+//
+// WARNING: OpenTagDiagnostic is a tag oracle. Reporting how
+// much of the tag matched lets an attacker forge a tag for any
+// message one byte at a time in at most 256*TagSize queries,
+// and the comparison is not constant time. It exists only to
+// diagnose byte order and framing bugs while developing against
+// other implementations. NEVER use it in production, NEVER
+// expose its result to a peer, and NEVER ship a binary built
+// with the aeaddebug build tag.
+//
+// If authentication succeeds, matched is TagSize. If it fails,
+// the plaintext is nil and matched is in [0, TagSize).
+//
+// It is only available with the aeaddebug build tag.
+func (a *AEAD) OpenTagDiagnostic(dst, nonce, ciphertext, additionalData []byte) (plaintext []byte, matched int, err error) {
+	checkNonce(nonce)
+	if len(ciphertext) < TagSize {
+		return nil, 0, errOpen
+	}
+	tag := ciphertext[len(ciphertext)-TagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-TagSize]
+
+	var s state
+	a.init(&s, nonce)
+	a.additionalData(&s, additionalData)
+
+	// Decrypt into a scratch buffer so that dst is never
+	// written to on failure.
+	out := make([]byte, len(ciphertext))
+	if a.iv == iv128a {
+		s.decrypt128a(out, ciphertext)
+	} else {
+		s.decrypt128(out, ciphertext)
+	}
+	a.finalize(&s, uint64(len(additionalData)), uint64(len(ciphertext)))
+
+	var expectedTag [TagSize]byte
+	s.tag(expectedTag[:])
+	for matched < TagSize && expectedTag[matched] == tag[matched] {
+		matched++
+	}
+	if matched != TagSize {
+		return nil, matched, errOpen
+	}
+	return append(dst, out...), matched, nil
+}
@@ -0,0 +1,61 @@
+//go:build aeaddebug
+// +build aeaddebug
+
+package ascon
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestOpenTagDiagnostic tests that OpenTagDiagnostic reports the
+// number of leading tag bytes that match.
+func TestOpenTagDiagnostic(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	plaintext := []byte("hello, world")
+	ad := []byte("additional data")
+	ciphertext := aead.Seal(nil, nonce, plaintext, ad)
+
+	got, n, err := aead.OpenTagDiagnostic(nil, nonce, ciphertext, ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != TagSize {
+		t.Fatalf("expected %d matching bytes, got %d", TagSize, n)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("expected %#x, got %#x", plaintext, got)
+	}
+
+	// Changing one byte of the tag leaves the bytes before it
+	// matching.
+	for i := 0; i < TagSize; i++ {
+		tweaked := append([]byte(nil), ciphertext...)
+		tweaked[len(plaintext)+i] ^= 1
+		got, n, err := aead.OpenTagDiagnostic(nil, nonce, tweaked, ad)
+		if err == nil {
+			t.Fatalf("#%d: expected an error", i)
+		}
+		if got != nil {
+			t.Fatalf("#%d: expected nil plaintext, got %#x", i, got)
+		}
+		if n != i {
+			t.Fatalf("#%d: expected %d matching bytes, got %d", i, i, n)
+		}
+	}
+
+	// Changing the ciphertext changes the entire tag, so the
+	// match count is almost always zero and never TagSize.
+	tweaked := append([]byte(nil), ciphertext...)
+	tweaked[0] ^= 1
+	if _, n, err := aead.OpenTagDiagnostic(nil, nonce, tweaked, ad); err == nil || n == TagSize {
+		t.Fatalf("expected a partial match, got %d (%v)", n, err)
+	}
+}
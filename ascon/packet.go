@@ -0,0 +1,72 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// PacketAEAD is an ASCON-128 AEAD that derives each nonce from
+// a static IV and a packet number.
+//
+// The nonce is computed as
+//
+//	nonce = iv ^ (0^64 || BE64(packetNumber))
+//
+// which is the same construction as RFC 9001, section 5.3.
+type PacketAEAD struct {
+	aead AEAD
+	iv   [NonceSize]byte
+}
+
+// NewWithIV creates an ASCON-128 AEAD that derives each nonce
+// from staticIV and a packet number.
+//
+// staticIV must be NonceSize bytes long and should be secret,
+// just like the key. Packet numbers must never be reused with
+// the same key and IV.
+func NewWithIV(key, staticIV []byte) (*PacketAEAD, error) {
+	if len(staticIV) != NonceSize {
+		return nil, errors.New("ascon: bad IV length")
+	}
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	p := &PacketAEAD{aead: *a}
+	copy(p.iv[:], staticIV)
+	return p, nil
+}
+
+// Overhead returns the maximum difference between the lengths
+// of a plaintext and its ciphertext.
+func (p *PacketAEAD) Overhead() int {
+	return p.aead.Overhead()
+}
+
+// Seal encrypts and authenticates plaintext, authenticates the
+// additional data and appends the result to dst, returning the
+// updated slice.
+//
+// See cipher.AEAD for more information.
+func (p *PacketAEAD) Seal(dst []byte, packetNumber uint64, plaintext, additionalData []byte) []byte {
+	nonce := p.nonce(packetNumber)
+	return p.aead.Seal(dst, nonce[:], plaintext, additionalData)
+}
+
+// Open decrypts and authenticates ciphertext, authenticates the
+// additional data and, if successful, appends the resulting
+// plaintext to dst, returning the updated slice.
+//
+// See cipher.AEAD for more information.
+func (p *PacketAEAD) Open(dst []byte, packetNumber uint64, ciphertext, additionalData []byte) ([]byte, error) {
+	nonce := p.nonce(packetNumber)
+	return p.aead.Open(dst, nonce[:], ciphertext, additionalData)
+}
+
+// nonce returns the nonce for the packet number.
+func (p *PacketAEAD) nonce(packetNumber uint64) [NonceSize]byte {
+	nonce := p.iv
+	v := binary.BigEndian.Uint64(nonce[NonceSize-8:])
+	binary.BigEndian.PutUint64(nonce[NonceSize-8:], v^packetNumber)
+	return nonce
+}
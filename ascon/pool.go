@@ -0,0 +1,105 @@
+package ascon
+
+import (
+	"runtime"
+	"sync"
+)
+
+// maxPooledSize is the largest plaintext buffer that Release
+// returns to the pool. Larger buffers are left to the garbage
+// collector so that one large message does not pin memory.
+const maxPooledSize = 64 * 1024
+
+// PooledAEAD is an ASCON-128 AEAD whose Open decrypts into
+// pooled buffers.
+//
+// It is intended for decryption-heavy servers: once the pool is
+// warm, Open does not allocate. The expected tag is already
+// computed on the stack, so only the plaintext is pooled.
+type PooledAEAD struct {
+	aead *AEAD
+	pool sync.Pool
+}
+
+// NewPooledAEAD creates a PooledAEAD using ASCON-128.
+func NewPooledAEAD(key []byte) (*PooledAEAD, error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	return &PooledAEAD{aead: a}, nil
+}
+
+// Plaintext is a pooled plaintext returned by PooledAEAD.Open.
+type Plaintext struct {
+	p   *PooledAEAD
+	buf []byte
+}
+
+// Bytes returns the plaintext.
+//
+// The slice is only valid until Release is called.
+func (pt *Plaintext) Bytes() []byte {
+	return pt.buf
+}
+
+// Release zeroes the plaintext and returns its buffer to the
+// pool.
+//
+// After calling Release, neither pt nor any slice returned by
+// Bytes may be used. Calling Release more than once panics.
+func (pt *Plaintext) Release() {
+	if pt.p == nil {
+		panic("ascon: Plaintext released twice")
+	}
+	buf := pt.buf[:cap(pt.buf)]
+	for i := range buf {
+		buf[i] = 0
+	}
+	runtime.KeepAlive(buf)
+	p := pt.p
+	pt.p = nil
+	if cap(buf) > maxPooledSize {
+		pt.buf = nil
+	} else {
+		pt.buf = buf[:0]
+	}
+	p.pool.Put(pt)
+}
+
+func (p *PooledAEAD) NonceSize() int {
+	return NonceSize
+}
+
+func (p *PooledAEAD) Overhead() int {
+	return TagSize
+}
+
+// Seal is the same as AEAD.Seal.
+func (p *PooledAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	return p.aead.Seal(dst, nonce, plaintext, additionalData)
+}
+
+// Open authenticates and decrypts ciphertext into a pooled
+// buffer.
+//
+// The caller owns the returned Plaintext and must call its
+// Release method once it is done with the plaintext. Until
+// then, the buffer is not reused. Forgetting to call Release is
+// safe, but the buffer is then garbage collected without being
+// zeroed.
+func (p *PooledAEAD) Open(nonce, ciphertext, additionalData []byte) (*Plaintext, error) {
+	pt, ok := p.pool.Get().(*Plaintext)
+	if !ok {
+		pt = &Plaintext{}
+	}
+	buf, err := p.aead.Open(pt.buf[:0], nonce, ciphertext, additionalData)
+	if err != nil {
+		// Open has already zeroed anything it wrote.
+		p.pool.Put(pt)
+		return nil, err
+	}
+	pt.p = p
+	pt.buf = buf
+	return pt, nil
+}
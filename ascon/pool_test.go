@@ -0,0 +1,94 @@
+package ascon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPooledAEAD(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 1
+	nonce := make([]byte, NonceSize)
+	p, err := NewPooledAEAD(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("hello, world")
+	ciphertext := p.Seal(nil, nonce, plaintext, nil)
+	if want := c.Seal(nil, nonce, plaintext, nil); !bytes.Equal(ciphertext, want) {
+		t.Fatalf("expected %#x, got %#x", want, ciphertext)
+	}
+
+	pt, err := p.Open(nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := pt.Bytes()
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("expected %q, got %q", plaintext, got)
+	}
+
+	// Released buffers are zeroed before they can be reused.
+	pt.Release()
+	if !bytes.Equal(got, make([]byte, len(got))) {
+		t.Fatalf("expected zeros, got %#x", got)
+	}
+	mustPanic(t, "ascon: Plaintext released twice", pt.Release)
+
+	ciphertext[0] ^= 1
+	if _, err := p.Open(nonce, ciphertext, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestPooledAEADAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+	key := make([]byte, KeySize)
+	key[0] = 1
+	nonce := make([]byte, NonceSize)
+	p, err := NewPooledAEAD(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := p.Seal(nil, nonce, make([]byte, 1024), nil)
+
+	n := testing.AllocsPerRun(100, func() {
+		pt, err := p.Open(nonce, ciphertext, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pt.Release()
+	})
+	if n != 0 {
+		t.Fatalf("expected zero allocations, got %v", n)
+	}
+}
+
+func BenchmarkPooledOpen1K(b *testing.B) {
+	b.SetBytes(1024)
+	b.ReportAllocs()
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	p, err := NewPooledAEAD(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ciphertext := p.Seal(nil, nonce, make([]byte, 1024), nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pt, err := p.Open(nonce, ciphertext, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		pt.Release()
+	}
+}
@@ -0,0 +1,64 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// PRFShortMaxSize is the maximum size in bytes of an
+// ASCON-PrfShort input.
+const PRFShortMaxSize = 16
+
+// ivPRFShort is the ASCON-PrfShort initialization vector.
+//
+// The length of the input in bits is added to its second byte.
+const ivPRFShort uint64 = 0x80004c8000000000
+
+// PRFShort returns the 16-byte ASCON-PrfShort output for the
+// key and input.
+//
+// ASCON-PrfShort is a variant of ASCON-Prf for inputs of up to
+// PRFShortMaxSize bytes. The input is loaded into the state
+// alongside the key, with its length in the initialization
+// vector instead of padding, so the whole computation is a
+// single permutation. Use NewPRF or NewMAC for longer inputs.
+//
+// PRFShort panics if the key is not KeySize bytes long or the
+// input is longer than PRFShortMaxSize. See PRFShortChecked.
+func PRFShort(key, in []byte) [16]byte {
+	out, err := PRFShortChecked(key, in)
+	if err != nil {
+		panic(err.Error())
+	}
+	return out
+}
+
+// PRFShortChecked is like PRFShort, but returns an error
+// instead of panicking.
+func PRFShortChecked(key, in []byte) ([16]byte, error) {
+	var out [16]byte
+	if len(key) != KeySize {
+		return out, errors.New("ascon: bad key length")
+	}
+	if len(in) > PRFShortMaxSize {
+		return out, errors.New("ascon: PRFShort input too long: " + strconv.Itoa(len(in)))
+	}
+	k0 := binary.BigEndian.Uint64(key[0:8])
+	k1 := binary.BigEndian.Uint64(key[8:16])
+	s := state{
+		x0: ivPRFShort ^ uint64(len(in))<<51,
+		x1: k0,
+		x2: k1,
+	}
+	if len(in) > 8 {
+		s.x3 = binary.BigEndian.Uint64(in[0:8])
+		s.x4 = be64n(in[8:])
+	} else {
+		s.x3 = be64n(in)
+	}
+	p12(&s)
+	binary.BigEndian.PutUint64(out[0:8], s.x3^k0)
+	binary.BigEndian.PutUint64(out[8:16], s.x4^k1)
+	return out, nil
+}
@@ -0,0 +1,93 @@
+package ascon
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestVectorsPRFShort tests PRFShort against
+// testdata/vectors_prfshort.txt, which has one vector for every
+// input length. See testdata/README.md for where the vectors
+// come from.
+func TestVectorsPRFShort(t *testing.T) {
+	vecs := readKAT(t, "vectors_prfshort.txt")
+	if len(vecs) != PRFShortMaxSize+1 {
+		t.Fatalf("expected %d vectors, got %d", PRFShortMaxSize+1, len(vecs))
+	}
+	for i, v := range vecs {
+		got := PRFShort(v["Key"], v["Msg"])
+		if !bytes.Equal(got[:], v["Tag"]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v["Tag"], got)
+		}
+	}
+}
+
+func TestPRFShort(t *testing.T) {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	// The length is part of the input, so trailing zeros
+	// matter.
+	seen := make(map[[16]byte]int)
+	in := make([]byte, PRFShortMaxSize)
+	for n := 0; n <= PRFShortMaxSize; n++ {
+		out, err := PRFShortChecked(key, in[:n])
+		if err != nil {
+			t.Fatalf("#%d: %v", n, err)
+		}
+		if m, ok := seen[out]; ok {
+			t.Fatalf("#%d: same output as #%d", n, m)
+		}
+		seen[out] = n
+	}
+
+	// PRFShort is not ASCON-Prf.
+	x, err := NewPRF(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, 16)
+	x.Read(want)
+	if got := PRFShort(key, nil); bytes.Equal(got[:], want) {
+		t.Fatal("PRFShort matches ASCON-Prf")
+	}
+
+	if _, err := PRFShortChecked(key, make([]byte, PRFShortMaxSize+1)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := PRFShortChecked(key[:KeySize-1], nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	mustPanic(t, "ascon: PRFShort input too long: 17", func() {
+		PRFShort(key, make([]byte, PRFShortMaxSize+1))
+	})
+}
+
+func BenchmarkPRFShort16(b *testing.B) {
+	key := make([]byte, KeySize)
+	in := make([]byte, 16)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		PRFShort(key, in)
+	}
+}
+
+// BenchmarkPRF16 is comparable to BenchmarkPRFShort16, except
+// that it excludes the permutation NewPRF spends on the key.
+func BenchmarkPRF16(b *testing.B) {
+	key := make([]byte, KeySize)
+	in := make([]byte, 16)
+	out := make([]byte, 16)
+	x, err := NewPRF(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		x.Reset()
+		x.Write(in)
+		x.Read(out)
+	}
+}
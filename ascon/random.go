@@ -0,0 +1,111 @@
+package ascon
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// RandomSealer generates random bytes and seals them for
+// export.
+type RandomSealer struct {
+	aead AEAD
+	rand io.Reader
+}
+
+// NewRandomSealer creates a RandomSealer that seals random bytes
+// with ASCON-128.
+//
+// Random bytes and nonces are read from rand. If rand is nil,
+// crypto/rand.Reader is used.
+func NewRandomSealer(key []byte, rand io.Reader) (*RandomSealer, error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	return &RandomSealer{aead: *a, rand: rand}, nil
+}
+
+// Seal reads n random bytes and seals them under a fresh random
+// nonce.
+//
+// The result is
+//
+//	nonce || ciphertext || tag
+//
+// which is NonceSize+n+TagSize bytes long. Seal returns an error
+// if n is negative or the result would be too large for an int.
+func (r *RandomSealer) Seal(n int) ([]byte, error) {
+	if n < 0 || n > maxInt-NonceSize-TagSize {
+		return nil, errors.New("ascon: invalid random length")
+	}
+	out := make([]byte, NonceSize+n, NonceSize+n+TagSize)
+	if _, err := io.ReadFull(r.reader(), out); err != nil {
+		return nil, err
+	}
+	// Encrypt in place so that the random bytes only ever
+	// exist as ciphertext in the result.
+	nonce := out[:NonceSize]
+	buf := out[NonceSize:]
+	r.aead.Seal(buf[:0], nonce, buf, nil)
+	return out[:cap(out)], nil
+}
+
+// Open authenticates and decrypts random bytes sealed by Seal.
+func (r *RandomSealer) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < NonceSize+TagSize {
+		return nil, errOpen
+	}
+	return r.aead.Open(nil, sealed[:NonceSize], sealed[NonceSize:], nil)
+}
+
+func (r *RandomSealer) reader() io.Reader {
+	if r.rand != nil {
+		return r.rand
+	}
+	return defaultRand
+}
+
+// defaultRand is the default source of randomness.
+var defaultRand = rand.Reader
+
+// SealWithRandomNonce reads a nonce from rand, seals plaintext
+// with it, and appends
+//
+//	nonce || ciphertext || tag
+//
+// to dst, returning the updated slice.
+//
+// Exactly NonceSize bytes are read from rand. If rand is nil,
+// crypto/rand.Reader is used. Tests can pass a deterministic
+// reader, such as a seeded math/rand.Rand, for reproducible
+// output. If rand returns fewer than NonceSize bytes,
+// SealWithRandomNonce returns io.ErrUnexpectedEOF or the
+// reader's error.
+func (a *AEAD) SealWithRandomNonce(rand io.Reader, dst, plaintext, additionalData []byte) ([]byte, error) {
+	if rand == nil {
+		rand = defaultRand
+	}
+	var nonce [NonceSize]byte
+	if _, err := io.ReadFull(rand, nonce[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	ret, out := subtle.SliceForAppend(dst, NonceSize)
+	copy(out, nonce[:])
+	return a.Seal(ret, nonce[:], plaintext, additionalData), nil
+}
+
+// OpenWithRandomNonce opens the output of SealWithRandomNonce
+// and, if successful, appends the resulting plaintext to dst,
+// returning the updated slice.
+func (a *AEAD) OpenWithRandomNonce(dst, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < NonceSize+TagSize {
+		return nil, errOpen
+	}
+	return a.Open(dst, sealed[:NonceSize], sealed[NonceSize:], additionalData)
+}
@@ -0,0 +1,75 @@
+package ascon
+
+// Sealer seals a single message whose additional data is
+// provided incrementally.
+//
+// Unlike AEADBuilder, the additional data is absorbed as soon as
+// it is added: full blocks are absorbed immediately and a
+// partial block is buffered until it is filled or Seal is
+// called. Buffers passed to AddAD can be reused as soon as it
+// returns.
+//
+// A Sealer must not be used after calling Seal. Doing so causes
+// a panic.
+type Sealer struct {
+	aead *AEAD
+	s    state
+	w    adWriter
+	// adLen is the number of bytes of additional data.
+	adLen uint64
+	// done is set by Seal.
+	done bool
+}
+
+// NewSealer creates an ASCON-128 Sealer for the message with
+// the provided nonce.
+func NewSealer(key, nonce []byte) (*Sealer, error) {
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateNonce(nonce, NonceSize); err != nil {
+		return nil, err
+	}
+	return a.Sealer(nonce), nil
+}
+
+// Sealer returns a Sealer for the message with the provided
+// nonce.
+//
+// The nonce must be NonceSize bytes long.
+func (a *AEAD) Sealer(nonce []byte) *Sealer {
+	checkNonce(nonce)
+
+	s := &Sealer{aead: a}
+	a.init(&s.s, nonce)
+	s.w = adWriter{s: &s.s, iv: a.iv}
+	return s
+}
+
+// AddAD appends p to the additional data.
+func (s *Sealer) AddAD(p []byte) {
+	if s.done {
+		panic("ascon: Sealer already used")
+	}
+	s.w.Write(p)
+	s.adLen += uint64(len(p))
+}
+
+// Seal is like AEAD.Seal, except that the additional data is
+// the concatenation of each slice passed to AddAD.
+func (s *Sealer) Seal(dst, plaintext []byte) []byte {
+	if s.done {
+		panic("ascon: Sealer already used")
+	}
+	s.done = true
+
+	s.w.finish()
+	if s.aead.count(s.adLen, uint64(len(plaintext))) {
+		panic("ascon: key usage limit exceeded")
+	}
+	out := s.aead.seal(&s.s, dst, plaintext, s.adLen)
+	s.s = state{}
+	s.w = adWriter{}
+	return out
+}
@@ -0,0 +1,96 @@
+package ascon
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"math/rand"
+	"testing"
+)
+
+func TestSealer(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(0xDEADBEEF))
+			key := make([]byte, KeySize)
+			nonce := make([]byte, NonceSize)
+			rng.Read(key)
+			rng.Read(nonce)
+			c, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			aead := c.(*AEAD)
+
+			ad := make([]byte, 100)
+			plaintext := make([]byte, 37)
+			for i := 0; i < 200; i++ {
+				rng.Read(ad)
+				rng.Read(plaintext)
+				n := rng.Intn(len(ad) + 1)
+				want := aead.Seal(nil, nonce, plaintext, ad[:n])
+
+				s := aead.Sealer(nonce)
+				// Reuse a single scratch buffer for each chunk.
+				buf := make([]byte, len(ad))
+				for j := 0; j < n; {
+					m := rng.Intn(n-j+1) % 20
+					copy(buf, ad[j:j+m])
+					s.AddAD(buf[:m])
+					for k := range buf {
+						buf[k] = 0
+					}
+					j += m
+				}
+				got := s.Seal(nil, plaintext)
+				if !bytes.Equal(got, want) {
+					t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+				}
+				mustPanic(t, "ascon: Sealer already used", func() {
+					s.AddAD(nil)
+				})
+				mustPanic(t, "ascon: Sealer already used", func() {
+					s.Seal(nil, plaintext)
+				})
+			}
+		})
+	}
+}
+
+func TestNewSealer(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+
+	aead, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("hello, world!")
+	want := aead.Seal(nil, nonce, plaintext, []byte("header.field"))
+
+	s, err := NewSealer(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddAD([]byte("header"))
+	s.AddAD([]byte("."))
+	s.AddAD([]byte("field"))
+	got := s.Seal(nil, plaintext)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("expected %#x, got %#x", want, got)
+	}
+
+	if _, err := NewSealer(key[1:], nonce); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := NewSealer(key, nonce[1:]); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,140 @@
+package ascon
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+// sealStreamBufSize is the size of the buffer used by
+// SealStream. It must be a multiple of BlockSize128a.
+//
+// With an in-memory reader and writer,
+// BenchmarkStreamBufferSizes shows no measurable difference
+// between 256 bytes and 128 KiB because the permutation
+// dominates. The default is larger than needed there so that
+// readers and writers backed by files or sockets, where each
+// call is a system call, make fewer calls.
+const sealStreamBufSize = 32 * 1024
+
+// SealStream encrypts and authenticates the plaintext read from
+// r until EOF, authenticates the additional data, and writes the
+// ciphertext followed by the tag to dst.
+//
+// The output is the same as Seal(nil, nonce, plaintext,
+// additionalData), but the plaintext is processed in chunks, so
+// it is never held in memory in its entirety.
+//
+// If reading from r or writing to dst fails, SealStream returns
+// the error. The output written so far is incomplete and must
+// be discarded.
+func (a *AEAD) SealStream(dst io.Writer, r io.Reader, nonce, additionalData []byte) error {
+	return a.SealStreamBuffer(dst, r, nonce, additionalData, sealStreamBufSize)
+}
+
+// SealStreamBuffer is like SealStream, but reads the plaintext
+// in chunks of bufSize bytes instead of the default 32 KiB.
+//
+// The output does not depend on bufSize. Smaller buffers use
+// less memory and write each chunk of ciphertext sooner, which
+// helps latency-sensitive callers. Larger buffers make fewer
+// calls to r and dst.
+//
+// bufSize must be a positive multiple of BlockSize128a.
+func (a *AEAD) SealStreamBuffer(dst io.Writer, r io.Reader, nonce, additionalData []byte, bufSize int) error {
+	checkNonce(nonce)
+	if bufSize <= 0 || bufSize%BlockSize128a != 0 {
+		return errors.New("ascon: invalid buffer size: " + strconv.Itoa(bufSize))
+	}
+
+	var s state
+	a.init(&s, nonce)
+	a.additionalData(&s, additionalData)
+	a.count(uint64(len(additionalData)), 0)
+
+	buf := make([]byte, bufSize+TagSize)
+	return a.sealStream(&s, dst, r, buf, uint64(len(additionalData)))
+}
+
+// SealReaderSplit is like SealStream, but reads both the
+// additional data and the plaintext from r: the first adLen
+// bytes are the additional data and the rest, until EOF, is the
+// plaintext.
+//
+// The output is the same as Seal(nil, nonce, plaintext,
+// additionalData). Neither the additional data nor the
+// plaintext is held in memory in its entirety.
+//
+// If r returns fewer than adLen bytes, SealReaderSplit returns
+// io.ErrUnexpectedEOF without writing anything to dst.
+func (a *AEAD) SealReaderSplit(dst io.Writer, r io.Reader, adLen int, nonce []byte) error {
+	checkNonce(nonce)
+	if adLen < 0 {
+		return errors.New("ascon: invalid additional data length")
+	}
+
+	var s state
+	a.init(&s, nonce)
+	w := adWriter{s: &s, iv: a.iv}
+	buf := make([]byte, sealStreamBufSize+TagSize)
+	for rem := adLen; rem > 0; {
+		n := len(buf)
+		if rem < n {
+			n = rem
+		}
+		if _, err := io.ReadFull(r, buf[:n]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		w.Write(buf[:n])
+		rem -= n
+	}
+	w.finish()
+	a.count(uint64(adLen), 0)
+	return a.sealStream(&s, dst, r, buf, uint64(adLen))
+}
+
+// sealStream encrypts the plaintext read from r until EOF and
+// writes the ciphertext and tag to dst after adLen bytes of
+// additional data have been absorbed into s.
+//
+// Plaintext is read in chunks of len(buf)-TagSize bytes, which
+// must be a positive multiple of BlockSize128a.
+func (a *AEAD) sealStream(s *state, dst io.Writer, r io.Reader, buf []byte, adLen uint64) error {
+	chunk := len(buf) - TagSize
+	var ptLen uint64
+	for {
+		n, err := io.ReadFull(r, buf[:chunk])
+		if a.count(0, uint64(n)) {
+			panic("ascon: key usage limit exceeded")
+		}
+		ptLen += uint64(n)
+		switch err {
+		case nil:
+			// There might be more plaintext, so the final
+			// block has not been reached yet.
+			if a.iv == iv128a {
+				encryptBlocks128a(s, buf[:n], buf[:n])
+			} else {
+				s.encryptBlocks128(buf[:n], buf[:n])
+			}
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return err
+			}
+		case io.EOF, io.ErrUnexpectedEOF:
+			if a.iv == iv128a {
+				s.encrypt128a(buf[:n], buf[:n])
+			} else {
+				s.encrypt128(buf[:n], buf[:n])
+			}
+			a.finalize(s, adLen, ptLen)
+			s.tag(buf[n : n+TagSize])
+			_, err := dst.Write(buf[:n+TagSize])
+			return err
+		default:
+			return err
+		}
+	}
+}
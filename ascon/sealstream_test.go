@@ -0,0 +1,241 @@
+package ascon
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"math/rand"
+	"strconv"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSealStream(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+		{"LengthBlock", NewWithLengthBlock},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(0xDEADBEEF))
+			key := make([]byte, KeySize)
+			nonce := make([]byte, NonceSize)
+			rng.Read(key)
+			rng.Read(nonce)
+			c, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			aead := c.(*AEAD)
+			ad := []byte("additional data")
+
+			for _, n := range []int{
+				0, 1, 7, 8, 15, 16, 17,
+				sealStreamBufSize - 1, sealStreamBufSize, sealStreamBufSize + 1,
+				2*sealStreamBufSize + 5,
+			} {
+				plaintext := make([]byte, n)
+				rng.Read(plaintext)
+				want := aead.Seal(nil, nonce, plaintext, ad)
+
+				for name, r := range map[string]io.Reader{
+					"LimitReader": io.LimitReader(bytes.NewReader(plaintext), int64(n)),
+					"HalfReader":  iotest.HalfReader(bytes.NewReader(plaintext)),
+					"Chunked":     &chunkReader{rng: rng, p: plaintext},
+				} {
+					var got bytes.Buffer
+					if err := aead.SealStream(&got, r, nonce, ad); err != nil {
+						t.Fatalf("%s (%d): %v", name, n, err)
+					}
+					if !bytes.Equal(got.Bytes(), want) {
+						t.Fatalf("%s (%d): output mismatch", name, n)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSealStreamError(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	r := iotest.TimeoutReader(bytes.NewReader(make([]byte, 10)))
+	if err := aead.SealStream(io.Discard, r, nonce, nil); err != iotest.ErrTimeout {
+		t.Fatalf("expected %v, got %v", iotest.ErrTimeout, err)
+	}
+
+	errWrite := errors.New("write failed")
+	w := errWriter{err: errWrite}
+	r = bytes.NewReader(make([]byte, 10))
+	if err := aead.SealStream(w, r, nonce, nil); err != errWrite {
+		t.Fatalf("expected %v, got %v", errWrite, err)
+	}
+}
+
+func TestSealReaderSplit(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func([]byte) (cipher.AEAD, error)
+	}{
+		{"128", New128},
+		{"128a", New128a},
+		{"LengthBlock", NewWithLengthBlock},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(0xDEADBEEF))
+			key := make([]byte, KeySize)
+			nonce := make([]byte, NonceSize)
+			rng.Read(key)
+			rng.Read(nonce)
+			c, err := tc.fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			aead := c.(*AEAD)
+
+			for _, adLen := range []int{
+				0, 1, 7, 8, 9, 16, 17,
+				sealStreamBufSize - 1, sealStreamBufSize + 1,
+			} {
+				for _, ptLen := range []int{0, 1, 15, 16, 17, sealStreamBufSize + 3} {
+					input := make([]byte, adLen+ptLen)
+					rng.Read(input)
+					ad, plaintext := input[:adLen], input[adLen:]
+					want := aead.Seal(nil, nonce, plaintext, ad)
+
+					for name, r := range map[string]io.Reader{
+						"Reader":     bytes.NewReader(input),
+						"HalfReader": iotest.HalfReader(bytes.NewReader(input)),
+						"Chunked":    &chunkReader{rng: rng, p: input},
+					} {
+						var got bytes.Buffer
+						if err := aead.SealReaderSplit(&got, r, adLen, nonce); err != nil {
+							t.Fatalf("%s (%d, %d): %v", name, adLen, ptLen, err)
+						}
+						if !bytes.Equal(got.Bytes(), want) {
+							t.Fatalf("%s (%d, %d): output mismatch", name, adLen, ptLen)
+						}
+					}
+				}
+			}
+
+			// Too little additional data.
+			for _, n := range []int{0, 1, 9} {
+				var got bytes.Buffer
+				r := bytes.NewReader(make([]byte, n))
+				if err := aead.SealReaderSplit(&got, r, 10, nonce); err != io.ErrUnexpectedEOF {
+					t.Fatalf("%d: expected %v, got %v", n, io.ErrUnexpectedEOF, err)
+				}
+				if got.Len() != 0 {
+					t.Fatalf("%d: wrote %d bytes", n, got.Len())
+				}
+			}
+			if err := aead.SealReaderSplit(io.Discard, bytes.NewReader(nil), -1, nonce); err == nil {
+				t.Fatal("expected an error for a negative length")
+			}
+		})
+	}
+}
+
+func TestSealStreamBuffer(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	for _, fn := range []func([]byte) (cipher.AEAD, error){New128, New128a} {
+		c, err := fn(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aead := c.(*AEAD)
+		ad := []byte("additional data")
+
+		for _, n := range []int{0, 1, 15, 16, 17, 1000, 4096, 10000} {
+			plaintext := make([]byte, n)
+			rng.Read(plaintext)
+			want := aead.Seal(nil, nonce, plaintext, ad)
+			for _, size := range []int{16, 32, 48, 1024, 4096, 64 * 1024} {
+				var got bytes.Buffer
+				r := &chunkReader{rng: rng, p: plaintext}
+				if err := aead.SealStreamBuffer(&got, r, nonce, ad, size); err != nil {
+					t.Fatalf("(%d, %d): %v", n, size, err)
+				}
+				if !bytes.Equal(got.Bytes(), want) {
+					t.Fatalf("(%d, %d): output mismatch", n, size)
+				}
+			}
+		}
+
+		for _, size := range []int{-16, 0, 1, 8, 15, 17, 24} {
+			err := aead.SealStreamBuffer(io.Discard, bytes.NewReader(nil), nonce, ad, size)
+			if err == nil {
+				t.Fatalf("%d: expected an error", size)
+			}
+		}
+	}
+}
+
+// BenchmarkStreamBufferSizes measures SealStreamBuffer over
+// 1 MiB of plaintext with different buffer sizes. It justifies
+// sealStreamBufSize.
+func BenchmarkStreamBufferSizes(b *testing.B) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := New128a(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	aead := c.(*AEAD)
+	plaintext := make([]byte, 1<<20)
+
+	for _, size := range []int{256, 1024, 4096, 8192, 32 * 1024, 128 * 1024} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.SetBytes(int64(len(plaintext)))
+			r := bytes.NewReader(plaintext)
+			for i := 0; i < b.N; i++ {
+				r.Reset(plaintext)
+				if err := aead.SealStreamBuffer(io.Discard, r, nonce, nil, size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// chunkReader returns p in randomly sized chunks.
+type chunkReader struct {
+	rng *rand.Rand
+	p   []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.p) == 0 {
+		return 0, io.EOF
+	}
+	n := r.rng.Intn(len(r.p)) + 1
+	if n > len(p) {
+		n = len(p)
+	}
+	n = copy(p, r.p[:n])
+	r.p = r.p[n:]
+	return n, nil
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
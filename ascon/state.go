@@ -0,0 +1,103 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// State is an ASCON-Hash sponge with a configurable number of
+// permutation rounds.
+//
+// It is intended for research on reduced-round variants of
+// ASCON-Hash, such as studying security margins. Reduced-round
+// variants are not secure. Use NewHash for ASCON-Hash itself.
+//
+// State does not pad its input. A hash built from State must
+// pad the final block itself, as ASCON-Hash does by appending
+// 0x80 and zero bytes.
+type State struct {
+	s state
+}
+
+// NewHashState returns a State initialized for ASCON-Hash with
+// a 256-bit digest.
+//
+// Initialization always uses the full 12 rounds.
+func NewHashState() *State {
+	var s State
+	s.s.x0 = hashIV(256)
+	p12(&s.s)
+	return &s
+}
+
+// AbsorbBlock XORs b into the rate and then applies the
+// permutation with the given number of rounds.
+//
+// rounds must be in [1, 12].
+func (s *State) AbsorbBlock(b [HashBlockSize]byte, rounds int) {
+	checkRounds(rounds)
+	s.s.x0 ^= binary.BigEndian.Uint64(b[:])
+	permute(&s.s, rounds)
+}
+
+// SqueezeBlock returns the rate and then applies the
+// permutation with the given number of rounds.
+//
+// rounds must be in [1, 12].
+func (s *State) SqueezeBlock(rounds int) [HashBlockSize]byte {
+	checkRounds(rounds)
+	var b [HashBlockSize]byte
+	binary.BigEndian.PutUint64(b[:], s.s.x0)
+	permute(&s.s, rounds)
+	return b
+}
+
+// checkRounds panics if rounds is not in [1, 12].
+func checkRounds(rounds int) {
+	if rounds < 1 || rounds > 12 {
+		panic("ascon: invalid number of rounds: " + strconv.Itoa(rounds))
+	}
+}
+
+// permute applies the last rounds rounds of the 12-round
+// permutation, so permute(s, 12), permute(s, 8), and
+// permute(s, 6) are the same as p12, p8, and p6.
+func permute(s *state, rounds int) {
+	switch rounds {
+	case 12:
+		p12(s)
+	case 8:
+		p8(s)
+	case 6:
+		p6(s)
+	default:
+		for r := 12 - rounds; r < 12; r++ {
+			round(s, uint64((0xf-r)<<4|r))
+		}
+	}
+}
+
+// Permute applies the last rounds rounds of the 12-round ASCON
+// permutation to s, where s[0] is x0 and s[4] is x4.
+//
+// rounds must be in [1, 12]. Permute(s, 12) is the full
+// permutation.
+func Permute(s *[5]uint64, rounds int) {
+	checkRounds(rounds)
+	x := state{x0: s[0], x1: s[1], x2: s[2], x3: s[3], x4: s[4]}
+	permute(&x, rounds)
+	*s = [5]uint64{x.x0, x.x1, x.x2, x.x3, x.x4}
+}
+
+// PermuteBatch is like calling Permute on each of states.
+//
+// rounds must be in [1, 12].
+func PermuteBatch(states [][5]uint64, rounds int) {
+	checkRounds(rounds)
+	for i := range states {
+		s := &states[i]
+		x := state{x0: s[0], x1: s[1], x2: s[2], x3: s[3], x4: s[4]}
+		permute(&x, rounds)
+		*s = [5]uint64{x.x0, x.x1, x.x2, x.x3, x.x4}
+	}
+}
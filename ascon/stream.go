@@ -0,0 +1,615 @@
+package ascon
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// STREAMPrefixSize is the size in bytes of a STREAM nonce
+// prefix.
+const STREAMPrefixSize = NonceSize - 5
+
+// maxSTREAMChunks is the maximum number of chunks in a single
+// STREAM.
+const maxSTREAMChunks = math.MaxUint32 + 1
+
+var (
+	errSTREAMDone     = errors.New("ascon: STREAM already finished")
+	errSTREAMOverflow = errors.New("ascon: STREAM chunk counter overflow")
+	errSTREAMSize     = errors.New("ascon: invalid STREAM chunk size")
+)
+
+// STREAM implements the STREAM online authenticated encryption
+// construction from Hoang, Reyhanitabar, and Rogaway using
+// ASCON-128.
+//
+// A message is split into chunks of a fixed size. Each chunk is
+// sealed with the nonce
+//
+//	prefix || BE32(counter) || flag
+//
+// where counter is the index of the chunk and flag is 1 for the
+// final chunk and 0 otherwise. This prevents chunks from being
+// reordered, dropped, or truncated.
+//
+// Each chunk except the final chunk must contain exactly
+// chunkSize bytes of plaintext. The final chunk may contain
+// between zero and chunkSize bytes of plaintext. A STREAM can
+// contain at most 2^32 chunks.
+//
+// A STREAM must be used either to seal or to open a single
+// message, not both. Nonce prefixes must never be reused with
+// the same key.
+//
+// A long-lived STREAM can switch to a new key with Rekey. See
+// Rekey for how the sealer and opener coordinate.
+//
+// References:
+//
+//	[stream]: https://eprint.iacr.org/2015/189.pdf
+type STREAM struct {
+	aead      AEAD
+	prefix    [STREAMPrefixSize]byte
+	chunkSize int
+	// counter is the index of the next chunk.
+	counter uint64
+	// done is set after the final chunk.
+	done bool
+	// next is the key set by Rekey, if any.
+	next *AEAD
+}
+
+// rekeyAD is the additional data of the first chunk sealed with
+// a new key.
+var rekeyAD = []byte("ascon STREAM rekey")
+
+// NewSTREAM creates a STREAM that splits messages into chunks
+// of chunkSize bytes.
+//
+// noncePrefix must be STREAMPrefixSize bytes long. chunkSize
+// must be positive.
+func NewSTREAM(key, noncePrefix []byte, chunkSize int) (*STREAM, error) {
+	if len(noncePrefix) != STREAMPrefixSize {
+		return nil, errors.New("ascon: bad STREAM nonce prefix length")
+	}
+	if chunkSize <= 0 {
+		return nil, errors.New("ascon: bad STREAM chunk size")
+	}
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	s := &STREAM{
+		aead:      *a,
+		chunkSize: chunkSize,
+	}
+	copy(s.prefix[:], noncePrefix)
+	return s, nil
+}
+
+// ChunkSize returns the size in bytes of each plaintext chunk.
+func (s *STREAM) ChunkSize() int {
+	return s.chunkSize
+}
+
+// Overhead returns the difference between the lengths of a
+// plaintext chunk and its ciphertext.
+func (s *STREAM) Overhead() int {
+	return TagSize
+}
+
+// SealChunk encrypts and authenticates the next chunk of
+// plaintext and appends the result to dst, returning the updated
+// slice.
+//
+// last must be true for the final chunk and false otherwise.
+// SealChunk returns an error if the chunk has the wrong size or
+// if the STREAM has already been finished.
+func (s *STREAM) SealChunk(dst, plaintext []byte, last bool) ([]byte, error) {
+	if err := s.check(len(plaintext), last); err != nil {
+		return nil, err
+	}
+	nonce := s.nonce(s.counter, last)
+	s.advance(last)
+	if s.next != nil {
+		s.aead, s.next = *s.next, nil
+		return s.aead.Seal(dst, nonce[:], plaintext, rekeyAD), nil
+	}
+	return s.aead.Seal(dst, nonce[:], plaintext, nil), nil
+}
+
+// SealChunksParallel is like calling SealChunk for each
+// consecutive chunk of plaintext, but seals the chunks
+// concurrently using up to GOMAXPROCS goroutines.
+//
+// If last is false, the length of plaintext must be a multiple
+// of the chunk size. If last is true, the final chunk may be
+// partial or empty and is sealed as the final chunk.
+//
+// The result is appended to dst and is identical to the output
+// of sequential calls to SealChunk. dst and plaintext must not
+// overlap.
+func (s *STREAM) SealChunksParallel(dst, plaintext []byte, last bool) ([]byte, error) {
+	if s.done {
+		return nil, errSTREAMDone
+	}
+	if !last && len(plaintext)%s.chunkSize != 0 {
+		return nil, errSTREAMSize
+	}
+	if s.next != nil && (last || len(plaintext) > 0) {
+		// The first chunk sealed with a new key has different
+		// additional data, so seal it sequentially.
+		n := len(plaintext)
+		final := last && n <= s.chunkSize
+		if !final {
+			n = s.chunkSize
+		}
+		var err error
+		dst, err = s.SealChunk(dst, plaintext[:n], final)
+		if err != nil || final {
+			return dst, err
+		}
+		plaintext = plaintext[n:]
+	}
+
+	chunks := len(plaintext) / s.chunkSize
+	if last && (len(plaintext)%s.chunkSize != 0 || chunks == 0) {
+		chunks++
+	}
+	if chunks == 0 {
+		return dst, nil
+	}
+	if uint64(chunks) > maxSTREAMChunks-s.counter {
+		return nil, errSTREAMOverflow
+	}
+	ret, out := subtle.SliceForAppend(dst, len(plaintext)+chunks*TagSize)
+	if subtle.AnyOverlap(out, plaintext) {
+		panic("ascon: invalid buffer overlap")
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > chunks {
+		workers = chunks
+	}
+	// next is the index of the next chunk to seal.
+	var next int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1) - 1)
+				if i >= chunks {
+					return
+				}
+				pt := plaintext[i*s.chunkSize:]
+				if len(pt) > s.chunkSize {
+					pt = pt[:s.chunkSize]
+				}
+				nonce := s.nonce(s.counter+uint64(i), last && i == chunks-1)
+				off := i * (s.chunkSize + TagSize)
+				s.aead.Seal(out[off:off], nonce[:], pt, nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.counter += uint64(chunks)
+	s.done = last
+	return ret, nil
+}
+
+// OpenChunk decrypts and authenticates the next chunk of
+// ciphertext and, if successful, appends the resulting
+// plaintext to dst, returning the updated slice.
+//
+// last must be true for the final chunk and false otherwise. If
+// a chunk fails authentication the STREAM is not advanced.
+func (s *STREAM) OpenChunk(dst, ciphertext []byte, last bool) ([]byte, error) {
+	if len(ciphertext) < TagSize {
+		return nil, errOpen
+	}
+	if err := s.check(len(ciphertext)-TagSize, last); err != nil {
+		return nil, err
+	}
+	nonce := s.nonce(s.counter, last)
+	if s.next != nil {
+		return s.openRekey(dst, nonce[:], ciphertext, last)
+	}
+	out, err := s.aead.Open(dst, nonce[:], ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.advance(last)
+	return out, nil
+}
+
+// Rekey switches the STREAM to key at the next chunk boundary.
+//
+// When sealing, the next chunk and every chunk after it are
+// sealed with key. The first of those chunks is sealed with
+// additional data that marks it as the start of the new key.
+//
+// When opening, the opener must call Rekey with the same key
+// before it reaches that chunk. It can do so at any earlier
+// chunk boundary: until the marked chunk arrives, each chunk is
+// opened with the current key, then with key. The STREAM
+// switches to key once a marked chunk is authentic.
+//
+// The chunk counter and nonce prefix carry over to the new key,
+// so chunks cannot be reordered across the switch. Calling
+// Rekey again before the switch replaces the pending key.
+func (s *STREAM) Rekey(key []byte) error {
+	if s.done {
+		return errSTREAMDone
+	}
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return err
+	}
+	s.next = a
+	return nil
+}
+
+// openRekey opens a chunk while a key set by Rekey is pending.
+//
+// Chunks before the switch open with the current key on the
+// first attempt. Only the marked chunk is decrypted twice.
+func (s *STREAM) openRekey(dst, nonce, ciphertext []byte, last bool) ([]byte, error) {
+	// A failed Open zeroes its output, which clobbers
+	// ciphertext if the two overlap, so keep a copy for the
+	// second attempt.
+	saved := ciphertext
+	n := len(ciphertext) - TagSize
+	if cap(dst)-len(dst) >= n && subtle.AnyOverlap(dst[len(dst):len(dst)+n], ciphertext) {
+		saved = append([]byte(nil), ciphertext...)
+	}
+	out, err := s.aead.Open(dst, nonce, ciphertext, nil)
+	if err == nil {
+		s.advance(last)
+		return out, nil
+	}
+	out, err = s.next.Open(dst, nonce, saved, rekeyAD)
+	if err != nil {
+		return nil, err
+	}
+	s.aead, s.next = *s.next, nil
+	s.advance(last)
+	return out, nil
+}
+
+// check reports whether a chunk with n bytes of plaintext can
+// be processed next.
+func (s *STREAM) check(n int, last bool) error {
+	if s.done {
+		return errSTREAMDone
+	}
+	if s.counter >= maxSTREAMChunks {
+		return errSTREAMOverflow
+	}
+	if n > s.chunkSize || (!last && n != s.chunkSize) {
+		return errSTREAMSize
+	}
+	return nil
+}
+
+// advance moves to the next chunk.
+func (s *STREAM) advance(last bool) {
+	s.counter++
+	s.done = last
+}
+
+// nonce returns the nonce for the chunk at index i.
+func (s *STREAM) nonce(i uint64, last bool) [NonceSize]byte {
+	var nonce [NonceSize]byte
+	copy(nonce[:], s.prefix[:])
+	binary.BigEndian.PutUint32(nonce[STREAMPrefixSize:], uint32(i))
+	if last {
+		nonce[NonceSize-1] = 1
+	}
+	return nonce
+}
+
+// STREAMCiphertextLen returns the total length of the
+// ciphertext produced by sealing plaintextLen bytes of
+// plaintext with STREAM in chunks of chunkSize bytes.
+//
+// Every chunk, including a partial or empty final chunk, has
+// a TagSize-byte tag.
+func STREAMCiphertextLen(plaintextLen, chunkSize int) int {
+	if plaintextLen < 0 || chunkSize <= 0 {
+		panic("ascon: invalid STREAM length")
+	}
+	chunks := (plaintextLen + chunkSize - 1) / chunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return plaintextLen + chunks*TagSize
+}
+
+// STREAMPlaintextLen returns the total length of the plaintext
+// produced by opening ciphertextLen bytes of ciphertext sealed
+// with STREAM in chunks of chunkSize bytes.
+//
+// It is the inverse of STREAMCiphertextLen. It returns an error
+// if no plaintext length results in ciphertextLen.
+func STREAMPlaintextLen(ciphertextLen, chunkSize int) (int, error) {
+	if ciphertextLen < 0 || chunkSize <= 0 {
+		return 0, errSTREAMSize
+	}
+	full := chunkSize + TagSize
+	n := ciphertextLen / full
+	rem := ciphertextLen % full
+	switch {
+	case rem == 0 && n > 0:
+		// The final chunk is full.
+		return n * chunkSize, nil
+	case rem >= TagSize:
+		return n*chunkSize + rem - TagSize, nil
+	default:
+		return 0, errSTREAMSize
+	}
+}
+
+// StreamChunkSize is the size in bytes of each plaintext chunk
+// written by NewStreamWriter and NewWriter.
+//
+// Unlike the buffer size of SealStreamBuffer, the chunk size is
+// part of the ciphertext format: it determines which nonce seals
+// each byte. It is therefore fixed so that every reader and
+// writer agrees on it. Use NewSTREAM for other chunk sizes.
+const StreamChunkSize = 64 * 1024
+
+// NewStreamWriter returns a writer that encrypts data with
+// STREAM and writes the ciphertext to w.
+//
+// The ciphertext begins with a randomly generated
+// STREAMPrefixSize-byte nonce prefix followed by each sealed
+// chunk. Each chunk contains StreamChunkSize bytes of
+// plaintext, except for the final chunk which may be shorter.
+//
+// The caller must call Close to write the final chunk.
+// Otherwise, the ciphertext will fail to decrypt.
+func NewStreamWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+	var prefix [STREAMPrefixSize]byte
+	if _, err := rand.Read(prefix[:]); err != nil {
+		return nil, err
+	}
+	s, err := NewSTREAM(key, prefix[:], StreamChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(prefix[:]); err != nil {
+		return nil, err
+	}
+	return newStreamWriter(w, s), nil
+}
+
+// NewWriter returns a writer that encrypts data with STREAM
+// using the caller-provided nonce prefix and writes the
+// ciphertext to w.
+//
+// nonce must be STREAMPrefixSize bytes long and must never be
+// reused with the same key. Unlike NewStreamWriter, the nonce
+// is not written to w.
+//
+// The ciphertext is a sequence of sealed chunks with no other
+// framing:
+//
+//	chunk_0 || chunk_1 || ... || chunk_n
+//
+// Each chunk is StreamChunkSize bytes of plaintext followed by
+// a TagSize-byte tag, except for chunk_n which contains between
+// zero and StreamChunkSize bytes of plaintext followed by a tag.
+// Chunk i is sealed with the nonce
+//
+//	nonce || BE32(i) || flag
+//
+// where flag is 1 for chunk_n and 0 otherwise. See STREAM.
+//
+// The caller must call Close to write the final chunk.
+// Otherwise, the ciphertext will fail to decrypt.
+func NewWriter(w io.Writer, key, nonce []byte) (io.WriteCloser, error) {
+	s, err := NewSTREAM(key, nonce, StreamChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamWriter(w, s), nil
+}
+
+func newStreamWriter(w io.Writer, s *STREAM) *streamWriter {
+	return &streamWriter{
+		w:   w,
+		s:   s,
+		buf: make([]byte, 0, s.ChunkSize()+TagSize),
+	}
+}
+
+type streamWriter struct {
+	w io.Writer
+	s *STREAM
+	// buf is the current chunk of plaintext.
+	buf []byte
+	// err is the first error encountered.
+	err error
+}
+
+var (
+	_ io.WriteCloser  = (*streamWriter)(nil)
+	_ io.StringWriter = (*streamWriter)(nil)
+)
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data arrives
+		// since any chunk could be the final chunk.
+		if len(w.buf) == w.s.ChunkSize() {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(w.buf[len(w.buf):w.s.ChunkSize()], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		n += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (w *streamWriter) WriteString(s string) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(s) > 0 {
+		if len(w.buf) == w.s.ChunkSize() {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(w.buf[len(w.buf):w.s.ChunkSize()], s)
+		w.buf = w.buf[:len(w.buf)+c]
+		n += c
+		s = s[c:]
+	}
+	return n, nil
+}
+
+// Close seals and writes the final chunk.
+//
+// Close does not close the underlying writer.
+func (w *streamWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.flush(true); err != nil {
+		return err
+	}
+	w.err = errSTREAMDone
+	return nil
+}
+
+// flush seals and writes the current chunk.
+func (w *streamWriter) flush(last bool) error {
+	buf, err := w.s.SealChunk(w.buf[:0], w.buf, last)
+	if err == nil {
+		_, err = w.w.Write(buf)
+	}
+	w.buf = w.buf[:0]
+	if err != nil {
+		w.err = err
+	}
+	return err
+}
+
+// NewStreamReader returns a reader that decrypts ciphertext
+// written by NewStreamWriter.
+//
+// Plaintext is only returned after its chunk has been
+// authenticated. The reader returns io.EOF only after the final
+// chunk has been authenticated, so a truncated ciphertext
+// results in an error.
+func NewStreamReader(r io.Reader, key []byte) (io.Reader, error) {
+	var prefix [STREAMPrefixSize]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return NewReader(r, key, prefix[:])
+}
+
+// NewReader returns a reader that decrypts ciphertext written
+// by NewWriter with the same key and nonce.
+//
+// Plaintext is only returned after its chunk has been
+// authenticated. The reader returns io.EOF only after the final
+// chunk has been authenticated, so a truncated ciphertext
+// results in an error.
+func NewReader(r io.Reader, key, nonce []byte) (io.Reader, error) {
+	s, err := NewSTREAM(key, nonce, StreamChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{
+		r:     r,
+		s:     s,
+		buf:   make([]byte, 0, s.ChunkSize()+TagSize+1),
+		ptbuf: make([]byte, 0, s.ChunkSize()),
+	}, nil
+}
+
+type streamReader struct {
+	r io.Reader
+	s *STREAM
+	// buf holds the current chunk of ciphertext plus one byte
+	// of look-ahead used to detect the final chunk.
+	buf []byte
+	// ptbuf is the plaintext of the current chunk.
+	ptbuf []byte
+	// pt is the unread portion of ptbuf.
+	pt []byte
+	// err is the first error encountered.
+	err error
+}
+
+var _ io.Reader = (*streamReader)(nil)
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for len(r.pt) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.pt)
+	r.pt = r.pt[n:]
+	return n, nil
+}
+
+// next reads and opens the next chunk.
+func (r *streamReader) next() error {
+	size := r.s.ChunkSize() + TagSize
+
+	// buf might contain the look-ahead byte from the previous
+	// chunk.
+	n, err := io.ReadFull(r.r, r.buf[len(r.buf):size+1])
+	r.buf = r.buf[:len(r.buf)+n]
+	last := false
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return err
+	}
+
+	chunk := r.buf
+	if !last {
+		chunk = chunk[:size]
+	}
+	pt, err := r.s.OpenChunk(r.ptbuf[:0], chunk, last)
+	if err != nil {
+		return err
+	}
+	r.pt = pt
+	if last {
+		return io.EOF
+	}
+	r.buf = append(r.buf[:0], r.buf[size])
+	return nil
+}
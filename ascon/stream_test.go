@@ -0,0 +1,511 @@
+package ascon
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// sealSTREAM seals plaintext as a sequence of chunks.
+func sealSTREAM(t *testing.T, s *STREAM, plaintext []byte) [][]byte {
+	t.Helper()
+
+	var chunks [][]byte
+	for {
+		n := len(plaintext)
+		last := n <= s.ChunkSize()
+		if !last {
+			n = s.ChunkSize()
+		}
+		c, err := s.SealChunk(nil, plaintext[:n], last)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, c)
+		plaintext = plaintext[n:]
+		if last {
+			return chunks
+		}
+	}
+}
+
+// openSTREAM opens a sequence of chunks.
+func openSTREAM(s *STREAM, chunks [][]byte) ([]byte, error) {
+	var out []byte
+	for i, c := range chunks {
+		var err error
+		out, err = s.OpenChunk(out, c, i == len(chunks)-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func newTestSTREAM(t *testing.T, chunkSize int) (*STREAM, *STREAM) {
+	t.Helper()
+
+	key := make([]byte, KeySize)
+	prefix := make([]byte, STREAMPrefixSize)
+	rand.Read(key)
+	rand.Read(prefix)
+
+	sealer, err := NewSTREAM(key, prefix, chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener, err := NewSTREAM(key, prefix, chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealer, opener
+}
+
+func TestSTREAMRoundTrip(t *testing.T) {
+	const chunkSize = 64
+	plaintext := make([]byte, 5*chunkSize+1)
+	rand.Read(plaintext)
+
+	for _, n := range []int{
+		0, 1, chunkSize - 1, chunkSize, chunkSize + 1,
+		2 * chunkSize, 5 * chunkSize, 5*chunkSize + 1,
+	} {
+		sealer, opener := newTestSTREAM(t, chunkSize)
+		chunks := sealSTREAM(t, sealer, plaintext[:n])
+		got, err := openSTREAM(opener, chunks)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext[:n]) {
+			t.Fatalf("%d: expected %#x, got %#x", n, plaintext[:n], got)
+		}
+	}
+}
+
+func TestSTREAMReorder(t *testing.T) {
+	sealer, opener := newTestSTREAM(t, 16)
+	chunks := sealSTREAM(t, sealer, make([]byte, 16*3+5))
+	chunks[0], chunks[1] = chunks[1], chunks[0]
+	if _, err := openSTREAM(opener, chunks); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestSTREAMDrop(t *testing.T) {
+	sealer, opener := newTestSTREAM(t, 16)
+	chunks := sealSTREAM(t, sealer, make([]byte, 16*3+5))
+	chunks = append(chunks[:1], chunks[2:]...)
+	if _, err := openSTREAM(opener, chunks); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestSTREAMTruncate(t *testing.T) {
+	sealer, opener := newTestSTREAM(t, 16)
+	chunks := sealSTREAM(t, sealer, make([]byte, 16*3+5))
+	if _, err := openSTREAM(opener, chunks[:len(chunks)-1]); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestSTREAMMisuse(t *testing.T) {
+	sealer, _ := newTestSTREAM(t, 16)
+	if _, err := sealer.SealChunk(nil, make([]byte, 15), false); err != errSTREAMSize {
+		t.Fatalf("expected %v, got %v", errSTREAMSize, err)
+	}
+	if _, err := sealer.SealChunk(nil, make([]byte, 17), true); err != errSTREAMSize {
+		t.Fatalf("expected %v, got %v", errSTREAMSize, err)
+	}
+	if _, err := sealer.SealChunk(nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sealer.SealChunk(nil, nil, true); err != errSTREAMDone {
+		t.Fatalf("expected %v, got %v", errSTREAMDone, err)
+	}
+}
+
+func TestSTREAMLen(t *testing.T) {
+	for _, chunkSize := range []int{1, 7, 16, 64} {
+		for n := 0; n < 5*chunkSize+3; n++ {
+			sealer, _ := newTestSTREAM(t, chunkSize)
+			var total int
+			for _, c := range sealSTREAM(t, sealer, make([]byte, n)) {
+				total += len(c)
+			}
+			if got := STREAMCiphertextLen(n, chunkSize); got != total {
+				t.Fatalf("(%d, %d): expected %d, got %d",
+					n, chunkSize, total, got)
+			}
+			got, err := STREAMPlaintextLen(total, chunkSize)
+			if err != nil {
+				t.Fatalf("(%d, %d): %v", n, chunkSize, err)
+			}
+			if got != n {
+				t.Fatalf("(%d, %d): expected %d, got %d",
+					n, chunkSize, n, got)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		ciphertextLen, chunkSize int
+	}{
+		{0, 16},
+		{TagSize - 1, 16},
+		{16 + TagSize + 1, 16},
+		{2*(16+TagSize) + TagSize - 1, 16},
+		{-1, 16},
+		{TagSize, 0},
+	} {
+		_, err := STREAMPlaintextLen(tc.ciphertextLen, tc.chunkSize)
+		if err == nil {
+			t.Fatalf("(%d, %d): expected an error",
+				tc.ciphertextLen, tc.chunkSize)
+		}
+	}
+}
+
+func TestStreamReaderWriter(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+
+	plaintext := make([]byte, 3*StreamChunkSize+17)
+	rand.Read(plaintext)
+
+	for _, n := range []int{
+		0, 1, StreamChunkSize - 1, StreamChunkSize,
+		StreamChunkSize + 1, 3 * StreamChunkSize,
+		len(plaintext),
+	} {
+		var ct bytes.Buffer
+		w, err := NewStreamWriter(&ct, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(w, bytes.NewReader(plaintext[:n])); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+
+		want := STREAMPrefixSize + STREAMCiphertextLen(n, StreamChunkSize)
+		if ct.Len() != want {
+			t.Fatalf("%d: expected %d bytes, got %d", n, want, ct.Len())
+		}
+		sealed := append([]byte(nil), ct.Bytes()...)
+
+		r, err := NewStreamReader(&ct, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got bytes.Buffer
+		if _, err := io.Copy(&got, r); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if !bytes.Equal(got.Bytes(), plaintext[:n]) {
+			t.Fatalf("%d: plaintext mismatch", n)
+		}
+
+		// Truncating the ciphertext must result in an error.
+		r, err = NewStreamReader(bytes.NewReader(sealed[:len(sealed)-1]), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, r); err == nil {
+			t.Fatalf("%d: expected an error", n)
+		}
+	}
+}
+
+func TestStreamWriterWriteString(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+
+	plaintext := make([]byte, 2*StreamChunkSize+17)
+	rand.Read(plaintext)
+
+	var ct bytes.Buffer
+	w, err := NewStreamWriter(&ct, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(plaintext)
+	for len(s) > 0 {
+		n := rand.Intn(len(s)) + 1
+		if _, err := io.WriteString(w, s[:n]); err != nil {
+			t.Fatal(err)
+		}
+		s = s[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewStreamReader(&ct, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if _, err := io.Copy(&got, r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), plaintext) {
+		t.Fatal("plaintext mismatch")
+	}
+}
+
+func TestSTREAMRekey(t *testing.T) {
+	const chunkSize = 16
+	sealer, opener := newTestSTREAM(t, chunkSize)
+
+	key1 := make([]byte, KeySize)
+	key2 := make([]byte, KeySize)
+	rand.Read(key1)
+	rand.Read(key2)
+
+	plaintext := make([]byte, 8*chunkSize+3)
+	rand.Read(plaintext)
+
+	// Rekey after the second and fifth chunks.
+	var chunks [][]byte
+	for i := 0; i*chunkSize < len(plaintext); i++ {
+		switch i {
+		case 2:
+			if err := sealer.Rekey(key1); err != nil {
+				t.Fatal(err)
+			}
+		case 5:
+			if err := sealer.Rekey(key2); err != nil {
+				t.Fatal(err)
+			}
+		}
+		pt := plaintext[i*chunkSize:]
+		last := len(pt) <= chunkSize
+		if !last {
+			pt = pt[:chunkSize]
+		}
+		c, err := sealer.SealChunk(nil, pt, last)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, c)
+	}
+
+	// Without the new key, the first chunk with the new key
+	// fails.
+	plain := *opener
+	if _, err := openSTREAM(&plain, chunks); err != errOpen {
+		t.Fatalf("expected %v, got %v", errOpen, err)
+	}
+
+	// The opener learns each key before the switch, but not
+	// necessarily at the same chunk boundary. The marked chunks
+	// are opened in place.
+	var got []byte
+	for i, c := range chunks {
+		switch i {
+		case 1:
+			if err := opener.Rekey(key1); err != nil {
+				t.Fatal(err)
+			}
+		case 5:
+			if err := opener.Rekey(key2); err != nil {
+				t.Fatal(err)
+			}
+		}
+		out, err := opener.OpenChunk(c[:0], c, i == len(chunks)-1)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		got = append(got, out...)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("expected %#x, got %#x", plaintext, got)
+	}
+	if err := opener.Rekey(key1); err != errSTREAMDone {
+		t.Fatalf("expected %v, got %v", errSTREAMDone, err)
+	}
+}
+
+func TestSTREAMSealChunksParallel(t *testing.T) {
+	const chunkSize = 64
+	plaintext := make([]byte, 37*chunkSize+5)
+	rand.Read(plaintext)
+
+	key := make([]byte, KeySize)
+	rand.Read(key)
+
+	for _, n := range []int{
+		0, 1, chunkSize, chunkSize + 1, 8 * chunkSize,
+		37 * chunkSize, len(plaintext),
+	} {
+		for _, rekey := range []bool{false, true} {
+			sequential, _ := newTestSTREAM(t, chunkSize)
+			parallel := *sequential
+
+			if rekey {
+				for _, s := range []*STREAM{sequential, &parallel} {
+					if err := s.Rekey(key); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			// Seal a full chunk on its own first so that the
+			// counter does not start at zero.
+			head := make([]byte, chunkSize)
+			want, err := sequential.SealChunk(nil, head, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range sealSTREAM(t, sequential, plaintext[:n]) {
+				want = append(want, c...)
+			}
+
+			prefix := []byte("prefix")
+			got, err := parallel.SealChunksParallel(prefix, head, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err = parallel.SealChunksParallel(got, plaintext[:n], true)
+			if err != nil {
+				t.Fatalf("(%d, %v): %v", n, rekey, err)
+			}
+			if !bytes.Equal(got[:len(prefix)], prefix) {
+				t.Fatalf("(%d, %v): prefix modified", n, rekey)
+			}
+			if !bytes.Equal(got[len(prefix):], want) {
+				t.Fatalf("(%d, %v): output mismatch", n, rekey)
+			}
+			if _, err := parallel.SealChunksParallel(nil, nil, true); err != errSTREAMDone {
+				t.Fatalf("(%d, %v): expected %v, got %v", n, rekey, errSTREAMDone, err)
+			}
+		}
+	}
+
+	sealer, _ := newTestSTREAM(t, chunkSize)
+	if _, err := sealer.SealChunksParallel(nil, make([]byte, chunkSize+1), false); err != errSTREAMSize {
+		t.Fatalf("expected %v, got %v", errSTREAMSize, err)
+	}
+}
+
+func TestReaderWriter(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, STREAMPrefixSize)
+	rand.Read(key)
+	rand.Read(nonce)
+
+	plaintext := make([]byte, 2*StreamChunkSize+17)
+	rand.Read(plaintext)
+
+	for _, n := range []int{
+		0, 1, StreamChunkSize, StreamChunkSize + 1, len(plaintext),
+	} {
+		var ct bytes.Buffer
+		w, err := NewWriter(&ct, key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(plaintext[:n]); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+
+		// The framing is exactly the STREAM chunks.
+		s, err := NewSTREAM(key, nonce, StreamChunkSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := bytes.Join(sealSTREAM(t, s, plaintext[:n]), nil)
+		if !bytes.Equal(ct.Bytes(), want) {
+			t.Fatalf("%d: ciphertext mismatch", n)
+		}
+
+		r, err := NewReader(bytes.NewReader(want), key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext[:n]) {
+			t.Fatalf("%d: plaintext mismatch", n)
+		}
+
+		// Opening with the wrong nonce must fail.
+		bad := append([]byte(nil), nonce...)
+		bad[0] ^= 1
+		r, err = NewReader(bytes.NewReader(want), key, bad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(r); err == nil {
+			t.Fatalf("%d: expected an error", n)
+		}
+
+		// Dropping whole chunks must be detected.
+		if n > StreamChunkSize {
+			r, err = NewReader(bytes.NewReader(want[:StreamChunkSize+TagSize]), key, nonce)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.ReadAll(r); err == nil {
+				t.Fatalf("%d: expected an error", n)
+			}
+		}
+	}
+
+	if _, err := NewWriter(io.Discard, key, nonce[1:]); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := NewReader(bytes.NewReader(nil), key, nonce[1:]); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+// TestSTREAMRekeyAllocs tests that opening a chunk while a key
+// is pending does not allocate unless dst overlaps the chunk.
+func TestSTREAMRekeyAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	const chunkSize = 1024
+	sealer, opener := newTestSTREAM(t, chunkSize)
+
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	pt := make([]byte, chunkSize)
+	rekeyed := *sealer
+	old, err := sealer.SealChunk(nil, pt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := opener.Rekey(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := rekeyed.Rekey(key); err != nil {
+		t.Fatal(err)
+	}
+	marked, err := rekeyed.SealChunk(nil, pt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := make([]byte, 0, chunkSize)
+	for _, c := range [][]byte{old, marked} {
+		n := testing.AllocsPerRun(100, func() {
+			s := *opener
+			if _, err := s.OpenChunk(dst, c, false); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if n != 0 {
+			t.Fatalf("expected zero allocations, got %v", n)
+		}
+	}
+}
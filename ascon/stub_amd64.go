@@ -1,7 +1,7 @@
 // Code generated by command: go run asm.go -out out/ascon_amd64.s -stubs out/stub_amd64.go -pkg ascon. DO NOT EDIT.
 
-//go:build gc && !purego
-// +build gc,!purego
+//go:build gc && !purego && !faultinject
+// +build gc,!purego,!faultinject
 
 package ascon
 
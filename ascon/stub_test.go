@@ -0,0 +1,114 @@
+package ascon
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math/rand"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// stubs calls each function declared in the assembly stubs and
+// its generic counterpart with the same inputs and reports
+// whether the results match.
+var stubs = map[string]func(s state, src []byte) bool{
+	"p12": func(s state, _ []byte) bool {
+		want, got := s, s
+		p12Generic(&want)
+		p12(&got)
+		return want == got
+	},
+	"p8": func(s state, _ []byte) bool {
+		want, got := s, s
+		p8Generic(&want)
+		p8(&got)
+		return want == got
+	},
+	"p6": func(s state, _ []byte) bool {
+		want, got := s, s
+		p6Generic(&want)
+		p6(&got)
+		return want == got
+	},
+	"round": func(s state, _ []byte) bool {
+		want, got := s, s
+		roundGeneric(&want, 0xf0)
+		round(&got, 0xf0)
+		return want == got
+	},
+	"additionalData128a": func(s state, src []byte) bool {
+		want, got := s, s
+		additionalData128aGeneric(&want, src)
+		additionalData128a(&got, src)
+		return want == got
+	},
+	"encryptBlocks128a": func(s state, src []byte) bool {
+		want, got := s, s
+		wantDst := make([]byte, len(src))
+		gotDst := make([]byte, len(src))
+		encryptBlocks128aGeneric(&want, wantDst, src)
+		encryptBlocks128a(&got, gotDst, src)
+		return want == got && bytes.Equal(wantDst, gotDst)
+	},
+	"decryptBlocks128a": func(s state, src []byte) bool {
+		want, got := s, s
+		wantDst := make([]byte, len(src))
+		gotDst := make([]byte, len(src))
+		decryptBlocks128aGeneric(&want, wantDst, src)
+		decryptBlocks128a(&got, gotDst, src)
+		return want == got && bytes.Equal(wantDst, gotDst)
+	},
+}
+
+func TestImplementation(t *testing.T) {
+	switch impl := Implementation(); impl {
+	case "generic":
+		if _, err := os.Stat("stub_" + runtime.GOARCH + ".go"); err == nil && !purego {
+			t.Fatalf("expected %q, got %q", runtime.GOARCH, impl)
+		}
+	case runtime.GOARCH:
+		if purego {
+			t.Fatalf("expected %q, got %q", "generic", impl)
+		}
+	default:
+		t.Fatalf("unexpected implementation: %q", impl)
+	}
+}
+
+// TestStubs is a smoke test for the functions declared in the
+// assembly stubs.
+//
+// It calls each function once on the current GOARCH, so a stub
+// without an implementation fails to link, and checks that
+// stubs covers every function declared in stub_$GOARCH.go.
+func TestStubs(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	src := make([]byte, 4*BlockSize128a)
+	rng.Read(src)
+	for name, fn := range stubs {
+		if !fn(randState(rng), src) {
+			t.Fatalf("%s: mismatch with generic implementation", name)
+		}
+	}
+
+	path := "stub_" + runtime.GOARCH + ".go"
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		t.Skipf("no stubs for %s", runtime.GOARCH)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body != nil {
+			continue
+		}
+		if _, ok := stubs[fn.Name.Name]; !ok {
+			t.Errorf("%s: %s is not tested", path, fn.Name.Name)
+		}
+	}
+}
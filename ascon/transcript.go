@@ -0,0 +1,82 @@
+package ascon
+
+import "encoding/binary"
+
+// ChainingKeySize is the size in bytes of a chaining key
+// returned by Transcript.MixKey.
+const ChainingKeySize = 32
+
+// Operation labels for Transcript.
+const (
+	labelMixHash byte = 1 + iota
+	labelMixKey
+	labelDeriveKey
+	labelSum
+)
+
+// Transcript is a running hash of a handshake transcript, as
+// used by Noise-like protocols.
+//
+// It is built on ASCON-Xof. Every operation absorbs a one-byte
+// label that identifies the operation followed by the
+// length-prefixed data, so the sequence of operations is
+// uniquely encoded in the state:
+//
+//	label || BE64(len(data)) || data
+//
+// MixHash and MixKey use different labels, so mixing the same
+// data with each results in different transcripts. Outputs are
+// squeezed from a copy of the state after absorbing a further
+// label, so chaining keys and transcript hashes are
+// independent of each other and of future transcripts.
+type Transcript struct {
+	x sponge
+}
+
+// NewTranscript creates an empty Transcript.
+func NewTranscript() *Transcript {
+	var t Transcript
+	t.x.init(hashIV(0))
+	return &t
+}
+
+// MixHash absorbs data, such as a handshake message, into the
+// transcript.
+func (t *Transcript) MixHash(data []byte) {
+	t.mix(labelMixHash, data)
+}
+
+// MixKey absorbs data, such as a Diffie-Hellman output, into
+// the transcript and returns a ChainingKeySize-byte chaining
+// key derived from the updated transcript.
+func (t *Transcript) MixKey(data []byte) []byte {
+	t.mix(labelMixKey, data)
+	return t.derive(labelDeriveKey, make([]byte, ChainingKeySize))
+}
+
+// Sum appends the HashSize-byte hash of the transcript to b and
+// returns the resulting slice.
+//
+// It does not change the transcript.
+func (t *Transcript) Sum(b []byte) []byte {
+	out := make([]byte, HashSize)
+	return append(b, t.derive(labelSum, out)...)
+}
+
+// mix absorbs the labeled data.
+func (t *Transcript) mix(label byte, data []byte) {
+	var hdr [1 + 8]byte
+	hdr[0] = label
+	binary.BigEndian.PutUint64(hdr[1:], uint64(len(data)))
+	t.x.absorb(hdr[:])
+	t.x.absorb(data)
+}
+
+// derive fills out with output from a copy of the transcript
+// after absorbing label.
+func (t *Transcript) derive(label byte, out []byte) []byte {
+	x := t.x
+	x.absorb([]byte{label})
+	x.squeeze(out)
+	return out
+}
@@ -0,0 +1,86 @@
+package ascon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTranscript(t *testing.T) {
+	msg := []byte("handshake message")
+	dh := []byte("shared secret")
+
+	a := NewTranscript()
+	a.MixHash(msg)
+	ka := a.MixKey(dh)
+
+	b := NewTranscript()
+	b.MixHash(msg)
+	kb := b.MixKey(dh)
+
+	// Identical sequences converge.
+	if !bytes.Equal(ka, kb) {
+		t.Fatalf("expected %#x, got %#x", ka, kb)
+	}
+	if len(ka) != ChainingKeySize {
+		t.Fatalf("expected %d bytes, got %d", ChainingKeySize, len(ka))
+	}
+	if !bytes.Equal(a.Sum(nil), b.Sum(nil)) {
+		t.Fatal("transcript hashes differ")
+	}
+	if len(a.Sum(nil)) != HashSize {
+		t.Fatalf("expected %d bytes, got %d", HashSize, len(a.Sum(nil)))
+	}
+
+	// Sum does not change the transcript.
+	if !bytes.Equal(a.MixKey(nil), b.MixKey(nil)) {
+		t.Fatal("Sum changed the transcript")
+	}
+
+	// Different orders, operations, and framings diverge.
+	seqs := []func(*Transcript) []byte{
+		func(t *Transcript) []byte {
+			t.MixHash(msg)
+			return t.MixKey(dh)
+		},
+		func(t *Transcript) []byte {
+			k := t.MixKey(dh)
+			t.MixHash(msg)
+			return k
+		},
+		func(t *Transcript) []byte {
+			t.MixHash(dh)
+			return t.MixKey(msg)
+		},
+		func(t *Transcript) []byte {
+			t.MixKey(msg)
+			return t.MixKey(dh)
+		},
+		func(t *Transcript) []byte {
+			t.MixHash(append(msg[:len(msg):len(msg)], dh...))
+			return t.MixKey(nil)
+		},
+		func(t *Transcript) []byte {
+			t.MixHash(msg[:4])
+			t.MixHash(msg[4:])
+			return t.MixKey(dh)
+		},
+	}
+	keys := make(map[string]int)
+	sums := make(map[string]int)
+	for i, fn := range seqs {
+		tr := NewTranscript()
+		k := string(fn(tr))
+		if j, ok := keys[k]; ok {
+			t.Fatalf("#%d and #%d: same chaining key", i, j)
+		}
+		keys[k] = i
+		s := string(tr.Sum(nil))
+		if j, ok := sums[s]; ok {
+			t.Fatalf("#%d and #%d: same transcript hash", i, j)
+		}
+		sums[s] = i
+		if _, ok := keys[s]; ok {
+			t.Fatalf("#%d: transcript hash equals a chaining key", i)
+		}
+	}
+}
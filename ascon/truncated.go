@@ -0,0 +1,83 @@
+package ascon
+
+import (
+	"crypto/cipher"
+	"errors"
+	"strconv"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// MinTagSize is the smallest tag size accepted by
+// New128WithTagSize.
+const MinTagSize = 4
+
+// New128WithTagSize creates an ASCON-128 AEAD with tags of
+// tagSize bytes, which must be between MinTagSize and TagSize.
+//
+// The tag is the first tagSize bytes of the ASCON-128 tag, and
+// the ciphertext is unchanged. Shorter tags save bandwidth, but
+// an attacker can forge a message with probability 2^-(8*tagSize)
+// per attempt, so a 4-byte tag only suits links that limit or
+// detect repeated forgery attempts.
+func New128WithTagSize(key []byte, tagSize int) (cipher.AEAD, error) {
+	if tagSize < MinTagSize || tagSize > TagSize {
+		return nil, errors.New("ascon: invalid tag size: " + strconv.Itoa(tagSize))
+	}
+	a, err := newAEAD(key, iv128)
+	if err != nil {
+		return nil, err
+	}
+	return &truncatedAEAD{aead: *a, tagSize: tagSize}, nil
+}
+
+// truncatedAEAD is an ASCON-128 AEAD with truncated tags.
+type truncatedAEAD struct {
+	aead    AEAD
+	tagSize int
+}
+
+var _ cipher.AEAD = (*truncatedAEAD)(nil)
+
+func (t *truncatedAEAD) NonceSize() int {
+	return NonceSize
+}
+
+func (t *truncatedAEAD) Overhead() int {
+	return t.tagSize
+}
+
+func (t *truncatedAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	checkNonce(nonce)
+	checkPlaintextLen(len(plaintext))
+	ret, out := subtle.SliceForAppend(dst, len(plaintext)+t.tagSize)
+	if subtle.InexactOverlap(out, plaintext) {
+		panic("ascon: invalid buffer overlap")
+	}
+
+	var s state
+	t.aead.init(&s, nonce)
+	t.aead.additionalData(&s, additionalData)
+	if t.aead.count(uint64(len(additionalData)), uint64(len(plaintext))) {
+		panic("ascon: key usage limit exceeded")
+	}
+	var tag [TagSize]byte
+	t.aead.sealDetached(&s, out[:len(plaintext)], tag[:], plaintext, uint64(len(additionalData)))
+	copy(out[len(plaintext):], tag[:])
+	return ret
+}
+
+func (t *truncatedAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	checkNonce(nonce)
+	if len(ciphertext) < t.tagSize {
+		return nil, errOpen
+	}
+	tag := ciphertext[len(ciphertext)-t.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-t.tagSize]
+
+	var s state
+	t.aead.init(&s, nonce)
+	t.aead.additionalData(&s, additionalData)
+	t.aead.count(uint64(len(additionalData)), uint64(len(ciphertext)))
+	return t.aead.openDetached(&s, dst, ciphertext, tag, uint64(len(additionalData)))
+}
@@ -0,0 +1,72 @@
+package ascon
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestNew128WithTagSize(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	full, err := New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for size := MinTagSize; size <= TagSize; size++ {
+		aead, err := New128WithTagSize(key, size)
+		if err != nil {
+			t.Fatalf("%d: %v", size, err)
+		}
+		if got := aead.Overhead(); got != size {
+			t.Fatalf("%d: expected Overhead %d, got %d", size, size, got)
+		}
+		for n := 0; n < 40; n++ {
+			plaintext := make([]byte, n)
+			ad := make([]byte, n%11)
+			rng.Read(plaintext)
+			rng.Read(ad)
+
+			// The output is the ASCON-128 output with a
+			// truncated tag.
+			want := full.Seal(nil, nonce, plaintext, ad)[:n+size]
+			ciphertext := aead.Seal(nil, nonce, plaintext, ad)
+			if !bytes.Equal(ciphertext, want) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x", size, n, want, ciphertext)
+			}
+
+			got, err := aead.Open(nil, nonce, ciphertext, ad)
+			if err != nil {
+				t.Fatalf("(%d, %d): %v", size, n, err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x", size, n, plaintext, got)
+			}
+
+			// Every bit of the tag is checked.
+			for i := n; i < len(ciphertext); i++ {
+				ciphertext[i] ^= 1 << uint(rng.Intn(8))
+				if _, err := aead.Open(nil, nonce, ciphertext, ad); err == nil {
+					t.Fatalf("(%d, %d): forgery at byte %d", size, n, i)
+				}
+				ciphertext[i] = want[i]
+			}
+		}
+		if _, err := aead.Open(nil, nonce, make([]byte, size-1), nil); err == nil {
+			t.Fatalf("%d: expected an error", size)
+		}
+	}
+
+	for _, size := range []int{-1, 0, 1, MinTagSize - 1, TagSize + 1} {
+		if _, err := New128WithTagSize(key, size); err == nil {
+			t.Fatalf("%d: expected an error", size)
+		}
+	}
+	if _, err := New128WithTagSize(key[:KeySize-1], TagSize); err == nil {
+		t.Fatal("expected an error")
+	}
+}
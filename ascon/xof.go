@@ -0,0 +1,121 @@
+package ascon
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+// MaxCustomizationSize is the maximum size in bytes of an
+// Ascon-CXOF128 customization string.
+const MaxCustomizationSize = 256
+
+// Initial states for Ascon-XOF128 and Ascon-CXOF128 from NIST
+// SP 800-232, after the initial permutation of their IVs,
+// 0x0000080000cc0003 and 0x0000080000cc0004.
+var (
+	xof128Init = state{
+		x0: 0xda82ce768d9447eb,
+		x1: 0xcc7ce6c75f1ef969,
+		x2: 0xe7508fd780085631,
+		x3: 0x0ee0ea53416b58cc,
+		x4: 0xe0547524db6f0bde,
+	}
+	cxof128Init = state{
+		x0: 0x675527c2a0e8de03,
+		x1: 0x43d12d7dc0377bbc,
+		x2: 0xe9901dec426e81b5,
+		x3: 0x2ab14907720780b6,
+		x4: 0x8f3f1d02d432bc46,
+	}
+)
+
+// XOF is an extendable-output function.
+//
+// Write absorbs more input and Read squeezes output. Write
+// panics if it is called after Read.
+type XOF interface {
+	io.Writer
+	io.Reader
+	// Reset resets the XOF to its initial state, keeping the
+	// customization string, if any.
+	Reset()
+	// Clone returns a copy of the XOF in its current state.
+	Clone() XOF
+}
+
+// NewXOF128 creates an Ascon-XOF128 XOF as standardized in NIST
+// SP 800-232.
+func NewXOF128() XOF {
+	x := &xof{initial: spongeLE{s: xof128Init}}
+	x.Reset()
+	return x
+}
+
+// NewCXOF creates an Ascon-CXOF128 XOF as standardized in NIST
+// SP 800-232.
+//
+// The customization string separates different uses of the
+// XOF: the bit length of custom and then custom itself are
+// absorbed before any input, so XOFs with different
+// customization strings produce unrelated output for the same
+// input. Ascon-CXOF128 uses its own initialization vector, so
+// its output differs from Ascon-XOF128 even if custom is empty.
+//
+// custom must be at most MaxCustomizationSize bytes long.
+func NewCXOF(custom []byte) (XOF, error) {
+	if len(custom) > MaxCustomizationSize {
+		return nil, errors.New("ascon: customization string too long: " +
+			strconv.Itoa(len(custom)))
+	}
+	x := &xof{initial: spongeLE{s: cxof128Init}}
+	x.initial.s.x0 ^= uint64(len(custom)) * 8
+	p12(&x.initial.s)
+	x.initial.absorb(custom)
+	x.initial.pad()
+	x.Reset()
+	return x, nil
+}
+
+// xof is Ascon-XOF128 or Ascon-CXOF128.
+type xof struct {
+	spongeLE
+	// initial is the state before any input, after the
+	// customization string has been absorbed.
+	initial spongeLE
+}
+
+var (
+	_ XOF             = (*xof)(nil)
+	_ io.StringWriter = (*xof)(nil)
+)
+
+func (x *xof) Write(p []byte) (int, error) {
+	if x.squeezing {
+		panic("ascon: Write after Read")
+	}
+	x.absorb(p)
+	return len(p), nil
+}
+
+func (x *xof) WriteString(s string) (int, error) {
+	if x.squeezing {
+		panic("ascon: Write after Read")
+	}
+	x.absorbString(s)
+	return len(s), nil
+}
+
+func (x *xof) Read(p []byte) (int, error) {
+	x.squeeze(p)
+	return len(p), nil
+}
+
+func (x *xof) Reset() {
+	x.spongeLE = x.initial
+}
+
+func (x *xof) Clone() XOF {
+	x0 := *x
+	return &x0
+}
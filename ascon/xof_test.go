@@ -0,0 +1,165 @@
+package ascon
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// TestXOFInit tests the precomputed initial Ascon-XOF128 and
+// Ascon-CXOF128 states.
+func TestXOFInit(t *testing.T) {
+	for _, tc := range []struct {
+		iv   uint64
+		want state
+	}{
+		{0x0000080000cc0003, xof128Init},
+		{0x0000080000cc0004, cxof128Init},
+	} {
+		s := state{x0: tc.iv}
+		p12(&s)
+		if s != tc.want {
+			t.Fatalf("%#x: expected %#x, got %#x", tc.iv, s, tc.want)
+		}
+	}
+}
+
+// TestVectorsXOF128 tests NewXOF128 against
+// testdata/vectors_xof128.txt. See testdata/README.md for where
+// the vectors come from.
+func TestVectorsXOF128(t *testing.T) {
+	for i, v := range readKAT(t, "vectors_xof128.txt") {
+		x := NewXOF128()
+		x.Write(v["Msg"])
+		got := make([]byte, len(v["MD"]))
+		x.Read(got)
+		if !bytes.Equal(got, v["MD"]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v["MD"], got)
+		}
+	}
+}
+
+// TestVectorsCXOF128 tests NewCXOF against
+// testdata/vectors_cxof128.txt, which has customization strings
+// from zero to MaxCustomizationSize bytes long. See
+// testdata/README.md for where the vectors come from.
+func TestVectorsCXOF128(t *testing.T) {
+	for i, v := range readKAT(t, "vectors_cxof128.txt") {
+		x, err := NewCXOF(v["Z"])
+		if err != nil {
+			t.Fatalf("#%d: %v", i+1, err)
+		}
+		x.Write(v["Msg"])
+		got := make([]byte, len(v["MD"]))
+		x.Read(got)
+		if !bytes.Equal(got, v["MD"]) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v["MD"], got)
+		}
+	}
+}
+
+func TestCXOF(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	msg := make([]byte, 100)
+	rng.Read(msg)
+
+	read := func(x XOF) []byte {
+		out := make([]byte, 64)
+		x.Read(out)
+		return out
+	}
+
+	// Different customization strings, including the empty
+	// string and Ascon-XOF128, give unrelated output.
+	seen := make(map[string]string)
+	x := NewXOF128()
+	x.Write(msg)
+	seen[string(read(x))] = "XOF128"
+	for _, custom := range []string{"", "a", "b", "ab", "protocol v1", "protocol v2"} {
+		x, err := NewCXOF([]byte(custom))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x.Write(msg)
+		out := string(read(x))
+		if prev, ok := seen[out]; ok {
+			t.Fatalf("%q: same output as %q", custom, prev)
+		}
+		seen[out] = custom
+	}
+
+	x, err := NewCXOF([]byte("custom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Write(msg)
+	want := read(x)
+
+	// Incremental writes and reads.
+	x.Reset()
+	for p := msg; len(p) > 0; {
+		n := rng.Intn(len(p) + 1)
+		x.Write(p[:n])
+		p = p[n:]
+	}
+	y := x.Clone()
+	got := make([]byte, len(want))
+	for p := got; len(p) > 0; {
+		n := rng.Intn(len(p) + 1)
+		x.Read(p[:n])
+		p = p[n:]
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("expected %#x, got %#x", want, got)
+	}
+	// The clone is independent.
+	if got := read(y); !bytes.Equal(got, want) {
+		t.Fatalf("expected %#x, got %#x", want, got)
+	}
+
+	mustPanic(t, "ascon: Write after Read", func() {
+		x.Write(msg)
+	})
+
+	if _, err := NewCXOF(make([]byte, MaxCustomizationSize)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCXOF(make([]byte, MaxCustomizationSize+1)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestXOFWriteString(t *testing.T) {
+	cxof, err := NewCXOF([]byte("custom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := make([]byte, 600)
+	rand.Read(msg)
+	for _, x := range []XOF{NewXOF128(), cxof} {
+		for n := 0; n <= len(msg); n++ {
+			x1 := x.Clone()
+			x2 := x.Clone()
+			x1.Write(msg[:n])
+			w, err := io.WriteString(x2, string(msg[:n]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w != n {
+				t.Fatalf("%d: expected %d, got %d", n, n, w)
+			}
+			want := make([]byte, 40)
+			got := make([]byte, 40)
+			x1.Read(want)
+			x2.Read(got)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+			}
+		}
+		mustPanic(t, "ascon: Write after Read", func() {
+			x.Read(make([]byte, 1))
+			io.WriteString(x, "x")
+		})
+	}
+}
@@ -63,7 +63,7 @@ func (s state) lfsr() lfsr { return s.load("lfsr") }
 func (s state) nfsr() lfsr { return s.load("nfsr") }
 
 func declareKeystream() {
-	TEXT("next", NOSPLIT, "func(s *state) uint32")
+	TEXT("nextAsm", NOSPLIT, "func(s *state) uint32")
 	Pragma("noescape")
 
 	s := loadState(Param("s"), GP64())
@@ -245,7 +245,7 @@ func (l lfsr) shift(x Register) {
 }
 
 func declareAccumulate() {
-	TEXT("accumulate", NOSPLIT, "func(reg, acc uint64, ms, pt uint16) (reg1, acc1 uint64)")
+	TEXT("accumulateAsm", NOSPLIT, "func(reg, acc uint64, ms, pt uint16) (reg1, acc1 uint64)")
 	Pragma("noescape")
 
 	reg := Load(Param("reg"), GP64())
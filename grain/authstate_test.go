@@ -0,0 +1,50 @@
+package grain
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+// equalAuthState reports whether a and b have the same
+// authenticator state: the accumulator and shift register.
+//
+// The comparison is constant time so that differential tests
+// do not get into the habit of comparing secret state with ==.
+func equalAuthState(a, b *state) bool {
+	return subtle.ConstantTimeCompare(authBytes(a), authBytes(b)) == 1
+}
+
+// authBytes returns the little-endian encoding of acc || reg.
+func authBytes(s *state) []byte {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint64(b[0:8], s.acc)
+	binary.LittleEndian.PutUint64(b[8:16], s.reg)
+	return b
+}
+
+func TestEqualAuthState(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for i := 0; i < 1000; i++ {
+		a := state{acc: rng.Uint64(), reg: rng.Uint64()}
+		// The LFSRs are not part of the authenticator.
+		a.lfsr.lo = rng.Uint64()
+		b := state{acc: a.acc, reg: a.reg}
+		if !equalAuthState(&a, &b) {
+			t.Fatalf("#%d: expected equal", i)
+		}
+
+		bit := uint64(1) << uint(rng.Intn(64))
+		c := b
+		c.acc ^= bit
+		if equalAuthState(&a, &c) {
+			t.Fatalf("#%d: acc differs by %#x, but states are equal", i, bit)
+		}
+		c = b
+		c.reg ^= bit
+		if equalAuthState(&a, &c) {
+			t.Fatalf("#%d: reg differs by %#x, but states are equal", i, bit)
+		}
+	}
+}
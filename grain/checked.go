@@ -0,0 +1,47 @@
+package grain
+
+import (
+	"errors"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// ErrBufferOverlap is returned by SealChecked and OpenChecked
+// when the output inexactly overlaps the input.
+var ErrBufferOverlap = errors.New("grain: invalid buffer overlap")
+
+// SealChecked is like Seal, but returns an error instead of
+// panicking if the nonce is the wrong size or if the output
+// inexactly overlaps plaintext.
+//
+// It is intended for callers that cannot otherwise guarantee
+// that their buffers are valid, such as those configured by
+// untrusted input. Seal remains the fast path.
+func (a *AEAD) SealChecked(dst, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if err := validateNonce(nonce, NonceSize); err != nil {
+		return nil, err
+	}
+	_, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+	if subtle.InexactOverlap(out, plaintext) {
+		return nil, ErrBufferOverlap
+	}
+	return a.Seal(dst, nonce, plaintext, additionalData), nil
+}
+
+// OpenChecked is like Open, but returns an error instead of
+// panicking if the nonce is the wrong size or if the output
+// inexactly overlaps ciphertext.
+func (a *AEAD) OpenChecked(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if err := validateNonce(nonce, NonceSize); err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < TagSize {
+		return nil, errOpen
+	}
+	n := len(ciphertext) - TagSize
+	_, out := subtle.SliceForAppend(dst, n)
+	if subtle.InexactOverlap(out, ciphertext[:n]) {
+		return nil, ErrBufferOverlap
+	}
+	return a.Open(dst, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,92 @@
+//go:build graindebug
+// +build graindebug
+
+package grain
+
+import "encoding/binary"
+
+// forceGeneric makes the key stream and authenticator use the
+// generic implementation even when assembly is available.
+//
+// It is only set by tests.
+var forceGeneric bool
+
+// AuthState is a snapshot of the authenticator generator.
+//
+// It is only available with the graindebug build tag.
+type AuthState struct {
+	// Acc is the accumulator.
+	Acc uint64
+	// Reg is the shift register.
+	Reg uint64
+}
+
+// SealTrace is like Seal, but also returns the state of the
+// authenticator generator after initialization and after each
+// byte of the authenticated message, which is the DER-encoded
+// length of the additional data, the additional data, the
+// plaintext, and the padding byte.
+//
+// The tag is the little-endian encoding of the accumulator in
+// the final AuthState.
+//
+// SealTrace is intended for comparing intermediate states with
+// other implementations and is much slower than Seal. It is
+// only available with the graindebug build tag.
+func (a *AEAD) SealTrace(dst, nonce, plaintext, additionalData []byte) ([]byte, []AuthState) {
+	checkNonce(nonce)
+	x := incremental{s: a.init(nonce)}
+	trace := []AuthState{x.authState()}
+
+	var buf der
+	for _, v := range derLen(&buf, len(additionalData)) {
+		x.authenticate([]byte{v})
+		trace = append(trace, x.authState())
+	}
+	for _, v := range additionalData {
+		x.authenticate([]byte{v})
+		trace = append(trace, x.authState())
+	}
+	for _, v := range plaintext {
+		var c [1]byte
+		x.encrypt(c[:], []byte{v})
+		dst = append(dst, c[0])
+		trace = append(trace, x.authState())
+	}
+	var tag [TagSize]byte
+	x.finish(tag[:])
+	trace = append(trace, x.authState())
+	return append(dst, tag[:]...), trace
+}
+
+// authState returns the current authenticator state.
+func (x *incremental) authState() AuthState {
+	return AuthState{Acc: x.s.acc, Reg: x.s.reg}
+}
+
+// encrypt encrypts and authenticates src.
+func (x *incremental) encrypt(dst, src []byte) {
+	if x.odd && len(src) > 0 {
+		v := src[0]
+		dst[0] = uint8(getkb(x.word)>>8) ^ v
+		x.s.accumulate8(uint8(getmb(x.word)>>8), v)
+		x.odd = false
+		src = src[1:]
+		dst = dst[1:]
+	}
+	for len(src) >= 2 {
+		word := next(&x.s)
+		v := binary.LittleEndian.Uint16(src)
+		binary.LittleEndian.PutUint16(dst, getkb(word)^v)
+		x.s.reg, x.s.acc = accumulate(x.s.reg, x.s.acc, getmb(word), v)
+		src = src[2:]
+		dst = dst[2:]
+	}
+	if len(src) > 0 {
+		x.word = next(&x.s)
+		v := src[0]
+		dst[0] = uint8(getkb(x.word)) ^ v
+		x.s.accumulate8(uint8(getmb(x.word)), v)
+		x.odd = true
+	}
+}
@@ -0,0 +1,116 @@
+//go:build graindebug
+// +build graindebug
+
+package grain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/ericlagergren/lwcrypto/grain/internal/ref"
+)
+
+// TestSealTrace tests SealTrace against the known answer tests
+// and each intermediate state against the bit-level reference
+// implementation.
+func TestSealTrace(t *testing.T) {
+	vecs, err := readVecs(filepath.Join("testdata", "little_endian.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vecs {
+		c, err := New(v.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aead := c.(*AEAD)
+		ct, trace := aead.SealTrace(nil, v.nonce, v.pt, v.ad)
+		if !bytes.Equal(ct, v.ct) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, v.ct, ct)
+		}
+
+		// One state after init, one per byte, and one after
+		// the padding byte. The DER length is one byte for
+		// short additional data.
+		want := 1 + 1 + len(v.ad) + len(v.pt) + 1
+		if len(v.ad) > shortInt {
+			t.Fatalf("#%d: unexpected long additional data", i+1)
+		}
+		if len(trace) != want {
+			t.Fatalf("#%d: expected %d states, got %d", i+1, want, len(trace))
+		}
+
+		tag := binary.LittleEndian.Uint64(v.ct[len(v.ct)-TagSize:])
+		if got := trace[len(trace)-1].Acc; got != tag {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, tag, got)
+		}
+
+		acc, reg := ref.Trace(v.key, v.nonce, v.pt, v.ad)
+		if len(acc) != len(trace) {
+			t.Fatalf("#%d: expected %d states, got %d", i+1, len(acc), len(trace))
+		}
+		for j, s := range trace {
+			if s.Acc != acc[j] {
+				t.Fatalf("#%d: state %d: expected acc %#x, got %#x", i+1, j, acc[j], s.Acc)
+			}
+			// The reference pads with a single bit while
+			// the fast implementation pads a whole byte, so
+			// the register differs after padding. It does
+			// not affect the tag.
+			if j < len(trace)-1 && s.Reg != reg[j] {
+				t.Fatalf("#%d: state %d: expected reg %#x, got %#x", i+1, j, reg[j], s.Reg)
+			}
+		}
+	}
+}
+
+// TestSealTraceFixed tests SealTrace against fixed states for
+// two of the known answer tests, so that a change to both the
+// implementation and internal/ref cannot go unnoticed.
+//
+// No C reference for Grain-128AEAD was available to produce
+// these states. They were recorded from SealTrace after
+// TestSealTrace checked every state but the last against
+// internal/ref. The final accumulator is the published tag. The
+// final register depends on this implementation's whole-byte
+// padding and has no published counterpart.
+func TestSealTraceFixed(t *testing.T) {
+	vecs, err := readVecs(filepath.Join("testdata", "little_endian.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		count int
+		want  []AuthState
+	}{
+		{1, []AuthState{
+			{0x2012c262fbd6736e, 0xb26dd6fb190a963b},
+			{0x2012c262fbd6736e, 0xb9b26dd6fb190a96},
+			{0x99a0afb400cf79f8, 0xfab9b26dd6fb190a},
+		}},
+		{35, []AuthState{
+			{0x2012c262fbd6736e, 0xb26dd6fb190a963b},
+			{0x927f1499e2dce555, 0xb9b26dd6fb190a96},
+			{0x927f1499e2dce555, 0xfab9b26dd6fb190a},
+			{0x927f1499e2dce555, 0x47fab9b26dd6fb19},
+			{0xd585ad2b8f0a1e4c, 0x5447fab9b26dd6fb},
+		}},
+	} {
+		v := vecs[tc.count-1]
+		c, err := New(v.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, trace := c.(*AEAD).SealTrace(nil, v.nonce, v.pt, v.ad)
+		if len(trace) != len(tc.want) {
+			t.Fatalf("#%d: expected %d states, got %d", tc.count, len(tc.want), len(trace))
+		}
+		for j, s := range trace {
+			if s != tc.want[j] {
+				t.Fatalf("#%d: state %d: expected %+v, got %+v", tc.count, j, tc.want[j], s)
+			}
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package grain
+
+import (
+	"encoding/binary"
+	"errors"
+	"runtime"
+
+	"github.com/ericlagergren/subtle"
+)
+
+var (
+	errNoAD     = errors.New("grain: BeginAD must be called first")
+	errADBegun  = errors.New("grain: BeginAD already called")
+	errADLength = errors.New("grain: additional data does not match declared length")
+	errMode     = errors.New("grain: mode cannot be changed after Write")
+	errBuffered = errors.New("grain: Decryptor is not in Streaming mode")
+)
+
+// DecryptMode determines when a Decryptor releases plaintext.
+type DecryptMode int
+
+const (
+	// BufferAll withholds all plaintext until Finish verifies
+	// the tag. If verification fails, the plaintext is zeroed
+	// and never released.
+	//
+	// BufferAll is the default.
+	BufferAll DecryptMode = iota
+	// Streaming releases plaintext through Unverified as soon
+	// as it is decrypted.
+	//
+	// Plaintext released before Finish has NOT been
+	// authenticated and might have been modified by an
+	// attacker. The caller must not act on it, for example by
+	// parsing it or writing it anywhere that outlives the
+	// message, until Finish succeeds, and must discard it if
+	// Finish fails.
+	Streaming
+)
+
+// Decryptor incrementally decrypts and authenticates a single
+// message.
+//
+// Because Grain-128AEAD prefixes the additional data with its
+// DER-encoded length, the length of the additional data must be
+// declared with BeginAD before any additional data or
+// ciphertext is written. Writes that do not agree with the
+// declared length fail immediately.
+//
+// The final TagSize bytes written with Write are treated as the
+// authentication tag. By default, no plaintext is released
+// until Finish verifies the tag. See DecryptMode.
+type Decryptor struct {
+	x incremental
+	// adLeft is the number of bytes of additional data that
+	// have yet to be written, or -1 if BeginAD has not been
+	// called.
+	adLeft int
+	// tag holds the most recent TagSize bytes of ciphertext,
+	// which might be the tag.
+	tag [TagSize]byte
+	// ntag is the number of bytes in tag.
+	ntag int
+	// pt is the plaintext decrypted so far that has not been
+	// released.
+	pt []byte
+	// mode is set by SetMode.
+	mode DecryptMode
+	// started is set after the first call to Write.
+	started bool
+	// err is the first error encountered.
+	err error
+}
+
+// NewDecryptor creates a Decryptor for the message sealed with
+// nonce.
+func (a *AEAD) NewDecryptor(nonce []byte) *Decryptor {
+	checkNonce(nonce)
+	return &Decryptor{
+		x:      incremental{s: a.init(nonce)},
+		adLeft: -1,
+	}
+}
+
+// BeginAD declares the total length of the additional data.
+//
+// BeginAD must be called exactly once, before any call to
+// WriteAD or Write. Use BeginAD(0) if there is no additional
+// data.
+func (d *Decryptor) BeginAD(totalLen int) error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.adLeft >= 0 {
+		return d.fail(errADBegun)
+	}
+	if totalLen < 0 {
+		return d.fail(errADLength)
+	}
+	var buf der
+	d.x.authenticate(derLen(&buf, totalLen))
+	d.adLeft = totalLen
+	return nil
+}
+
+// WriteAD authenticates the next part of the additional data.
+func (d *Decryptor) WriteAD(p []byte) error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.adLeft < 0 {
+		return d.fail(errNoAD)
+	}
+	if len(p) > d.adLeft {
+		return d.fail(errADLength)
+	}
+	d.x.authenticate(p)
+	d.adLeft -= len(p)
+	return nil
+}
+
+// SetMode sets the DecryptMode.
+//
+// SetMode must be called before Write.
+func (d *Decryptor) SetMode(mode DecryptMode) error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.started {
+		return d.fail(errMode)
+	}
+	d.mode = mode
+	return nil
+}
+
+// Write decrypts the next part of the ciphertext.
+//
+// In BufferAll mode, the decrypted plaintext is withheld until
+// Finish verifies the tag.
+func (d *Decryptor) Write(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	if d.adLeft != 0 {
+		if d.adLeft < 0 {
+			return 0, d.fail(errNoAD)
+		}
+		return 0, d.fail(errADLength)
+	}
+	d.started = true
+	n := len(p)
+
+	// Release the bytes held back in tag that are no longer
+	// among the last TagSize bytes.
+	if k := d.ntag + len(p) - TagSize; k > 0 && d.ntag > 0 {
+		if k > d.ntag {
+			k = d.ntag
+		}
+		d.decrypt(d.tag[:k])
+		d.ntag = copy(d.tag[:], d.tag[k:d.ntag])
+	}
+	if len(p) > TagSize {
+		d.decrypt(p[:len(p)-TagSize])
+		p = p[len(p)-TagSize:]
+	}
+	d.ntag += copy(d.tag[d.ntag:], p)
+	return n, nil
+}
+
+// Unverified appends the plaintext decrypted since the
+// previous call to dst and returns the updated slice.
+//
+// It returns an error unless the Decryptor is in Streaming
+// mode. The plaintext has not been authenticated; see
+// Streaming.
+//
+// The final TagSize bytes written might be the tag, so they are
+// only decrypted once more ciphertext is written.
+func (d *Decryptor) Unverified(dst []byte) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.mode != Streaming {
+		return nil, errBuffered
+	}
+	dst = append(dst, d.pt...)
+	d.pt = d.pt[:0]
+	return dst, nil
+}
+
+// Finish verifies the tag and, if successful, appends the
+// plaintext to dst and returns the updated slice.
+//
+// In Streaming mode, only the plaintext not yet released by
+// Unverified is appended.
+//
+// The Decryptor must not be used after calling Finish.
+func (d *Decryptor) Finish(dst []byte) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.adLeft != 0 {
+		if d.adLeft < 0 {
+			return nil, d.fail(errNoAD)
+		}
+		return nil, d.fail(errADLength)
+	}
+	if d.ntag < TagSize {
+		return nil, d.fail(errOpen)
+	}
+
+	var expectedTag [TagSize]byte
+	d.x.finish(expectedTag[:])
+	if subtle.ConstantTimeCompare(expectedTag[:], d.tag[:]) != 1 {
+		for i := range d.pt {
+			d.pt[i] = 0
+		}
+		runtime.KeepAlive(d.pt)
+		return nil, d.fail(errOpen)
+	}
+	d.err = errors.New("grain: Decryptor already finished")
+	return append(dst, d.pt...), nil
+}
+
+// decrypt decrypts ciphertext into d.pt.
+func (d *Decryptor) decrypt(ciphertext []byte) {
+	var out []byte
+	d.pt, out = subtle.SliceForAppend(d.pt, len(ciphertext))
+	d.x.decrypt(out, ciphertext)
+}
+
+// fail records err and returns it.
+func (d *Decryptor) fail(err error) error {
+	d.err = err
+	return err
+}
+
+// derLen returns the DER encoding of the length n using buf as
+// scratch space.
+func derLen(buf *der, n int) []byte {
+	if n <= shortInt {
+		buf[0] = byte(n)
+		return buf[:1]
+	}
+	*buf = encode(n)
+	return buf[:buf.len()]
+}
+
+// incremental processes a message in arbitrarily sized pieces.
+//
+// Each byte of the message consumes eight bits of key stream
+// and eight bits of MAC stream from the pre-output generator,
+// so a 32-bit word from next covers two bytes. incremental
+// keeps track of a partially consumed word.
+type incremental struct {
+	s state
+	// word is the current pre-output word.
+	word uint32
+	// odd is set when only the low byte of word has been used.
+	odd bool
+}
+
+// authenticate authenticates p without encrypting it.
+func (x *incremental) authenticate(p []byte) {
+	if x.odd && len(p) > 0 {
+		x.s.accumulate8(uint8(getmb(x.word)>>8), p[0])
+		x.odd = false
+		p = p[1:]
+	}
+	for len(p) >= 2 {
+		v := binary.LittleEndian.Uint16(p)
+		x.s.reg, x.s.acc = accumulate(x.s.reg, x.s.acc, getmb(next(&x.s)), v)
+		p = p[2:]
+	}
+	if len(p) > 0 {
+		x.word = next(&x.s)
+		x.s.accumulate8(uint8(getmb(x.word)), p[0])
+		x.odd = true
+	}
+}
+
+// decrypt decrypts and authenticates src.
+func (x *incremental) decrypt(dst, src []byte) {
+	if x.odd && len(src) > 0 {
+		dst[0] = uint8(getkb(x.word)>>8) ^ src[0]
+		x.s.accumulate8(uint8(getmb(x.word)>>8), dst[0])
+		x.odd = false
+		src = src[1:]
+		dst = dst[1:]
+	}
+	for len(src) >= 2 {
+		word := next(&x.s)
+		v := getkb(word) ^ binary.LittleEndian.Uint16(src)
+		binary.LittleEndian.PutUint16(dst, v)
+		x.s.reg, x.s.acc = accumulate(x.s.reg, x.s.acc, getmb(word), v)
+		src = src[2:]
+		dst = dst[2:]
+	}
+	if len(src) > 0 {
+		x.word = next(&x.s)
+		dst[0] = uint8(getkb(x.word)) ^ src[0]
+		x.s.accumulate8(uint8(getmb(x.word)), dst[0])
+		x.odd = true
+	}
+}
+
+// finish pads the message and writes the tag to dst.
+func (x *incremental) finish(dst []byte) {
+	x.authenticate([]byte{0x01})
+	x.s.tag(dst)
+}
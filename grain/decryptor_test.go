@@ -0,0 +1,214 @@
+package grain
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// split splits p into randomly sized pieces.
+func split(rng *rand.Rand, p []byte) [][]byte {
+	var pieces [][]byte
+	for len(p) > 0 {
+		n := rng.Intn(len(p)) + 1
+		pieces = append(pieces, p[:n])
+		p = p[n:]
+	}
+	return pieces
+}
+
+func TestDecryptor(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	for i := 0; i < 500; i++ {
+		ad := make([]byte, rng.Intn(300))
+		pt := make([]byte, rng.Intn(300))
+		rng.Read(ad)
+		rng.Read(pt)
+		ct := aead.Seal(nil, nonce, pt, ad)
+
+		d := aead.NewDecryptor(nonce)
+		if err := d.BeginAD(len(ad)); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range split(rng, ad) {
+			if err := d.WriteAD(p); err != nil {
+				t.Fatalf("#%d: %v", i, err)
+			}
+		}
+		for _, p := range split(rng, ct) {
+			if _, err := d.Write(p); err != nil {
+				t.Fatalf("#%d: %v", i, err)
+			}
+		}
+		got, err := d.Finish(nil)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(got, pt) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, pt, got)
+		}
+
+		// Tampering must be detected and no plaintext
+		// returned.
+		ct[rng.Intn(len(ct))] ^= 1
+		d = aead.NewDecryptor(nonce)
+		if err := d.BeginAD(len(ad)); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.WriteAD(ad); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.Write(ct); err != nil {
+			t.Fatal(err)
+		}
+		got, err = d.Finish(nil)
+		if err == nil || got != nil {
+			t.Fatalf("#%d: expected an error", i)
+		}
+	}
+}
+
+func TestDecryptorLengths(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	d := aead.NewDecryptor(nonce)
+	if _, err := d.Write([]byte{1}); err != errNoAD {
+		t.Fatalf("expected %v, got %v", errNoAD, err)
+	}
+
+	d = aead.NewDecryptor(nonce)
+	if err := d.BeginAD(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.WriteAD(make([]byte, 4)); err != errADLength {
+		t.Fatalf("expected %v, got %v", errADLength, err)
+	}
+
+	d = aead.NewDecryptor(nonce)
+	if err := d.BeginAD(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.WriteAD(make([]byte, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Write([]byte{1}); err != errADLength {
+		t.Fatalf("expected %v, got %v", errADLength, err)
+	}
+
+	d = aead.NewDecryptor(nonce)
+	if err := d.BeginAD(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Write(make([]byte, TagSize-1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Finish(nil); err != errOpen {
+		t.Fatalf("expected %v, got %v", errOpen, err)
+	}
+}
+
+func TestDecryptorStreaming(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	// open opens ct in Streaming mode and returns the
+	// plaintext released by Unverified and the result of
+	// Finish.
+	open := func(ct, ad []byte) (released, final []byte, err error) {
+		d := aead.NewDecryptor(nonce)
+		if err := d.SetMode(Streaming); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.BeginAD(len(ad)); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.WriteAD(ad); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range split(rng, ct) {
+			if _, err := d.Write(p); err != nil {
+				t.Fatal(err)
+			}
+			released, err = d.Unverified(released)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		final, err = d.Finish(nil)
+		return released, final, err
+	}
+
+	for i := 0; i < 200; i++ {
+		ad := make([]byte, rng.Intn(50))
+		pt := make([]byte, rng.Intn(300)+1)
+		rng.Read(ad)
+		rng.Read(pt)
+		ct := aead.Seal(nil, nonce, pt, ad)
+
+		released, final, err := open(ct, ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		// Everything except the plaintext decrypted from the
+		// last TagSize bytes written is released early.
+		if got := append(released, final...); !bytes.Equal(got, pt) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, pt, got)
+		}
+
+		// Tampering is still detected by Finish.
+		ct[rng.Intn(len(ct))] ^= 1
+		if _, final, err := open(ct, ad); err != errOpen || final != nil {
+			t.Fatalf("#%d: expected %v, got %v", i, errOpen, err)
+		}
+	}
+}
+
+func TestDecryptorMode(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	d := aead.NewDecryptor(nonce)
+	if _, err := d.Unverified(nil); err != errBuffered {
+		t.Fatalf("expected %v, got %v", errBuffered, err)
+	}
+	if err := d.BeginAD(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Write(make([]byte, 2*TagSize)); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetMode(Streaming); err != errMode {
+		t.Fatalf("expected %v, got %v", errMode, err)
+	}
+}
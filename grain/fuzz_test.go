@@ -0,0 +1,92 @@
+//go:build fuzz
+// +build fuzz
+
+package grain_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ericlagergren/lwcrypto/grain"
+	"github.com/ericlagergren/lwcrypto/grain/internal/ref"
+	rand "github.com/ericlagergren/saferand"
+)
+
+// TestFuzz compares Seal and Open against the bit-level
+// reference implementation, which shares no code with this
+// package.
+func TestFuzz(t *testing.T) {
+	d := 2 * time.Second
+	if testing.Short() {
+		d = 10 * time.Millisecond
+	}
+	if s := os.Getenv("GRAIN_FUZZ_TIMEOUT"); s != "" {
+		var err error
+		d, err = time.ParseDuration(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tm := time.NewTimer(d)
+
+	key := make([]byte, grain.KeySize)
+	nonce := make([]byte, grain.NonceSize)
+	// The reference is slow, so keep inputs small but long
+	// enough for the long form of the DER-encoded length.
+	plaintext := make([]byte, 1024)
+	ad := make([]byte, 1024)
+	for i := 0; ; i++ {
+		select {
+		case <-tm.C:
+			t.Logf("iters: %d", i)
+			return
+		default:
+		}
+
+		if _, err := rand.Read(key); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rand.Read(nonce); err != nil {
+			t.Fatal(err)
+		}
+		n := rand.Intn(len(plaintext))
+		if _, err := rand.Read(plaintext[:n]); err != nil {
+			t.Fatal(err)
+		}
+		plaintext := plaintext[:n]
+		m := rand.Intn(len(ad))
+		if _, err := rand.Read(ad[:m]); err != nil {
+			t.Fatal(err)
+		}
+		ad := ad[:m]
+
+		refAead, err := ref.New(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotAead, err := grain.New(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantCt := refAead.Seal(nil, nonce, plaintext, ad)
+		gotCt := gotAead.Seal(nil, nonce, plaintext, ad)
+		if !bytes.Equal(wantCt, gotCt) {
+			t.Fatalf("expected %#x, got %#x", wantCt, gotCt)
+		}
+
+		wantPt, err := refAead.Open(nil, nonce, wantCt, ad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotPt, err := gotAead.Open(nil, nonce, wantCt, ad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(wantPt, gotPt) {
+			t.Fatalf("expected %#x, got %#x", wantPt, gotPt)
+		}
+	}
+}
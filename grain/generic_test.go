@@ -0,0 +1,57 @@
+//go:build graindebug
+// +build graindebug
+
+package grain
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// TestSealGeneric tests that Seal produces the same output with
+// the assembly and generic implementations for additional data
+// lengths that use the short and long forms of the DER-encoded
+// length.
+func TestSealGeneric(t *testing.T) {
+	if Implementation() == "generic" {
+		t.Skip("no assembly implementation")
+	}
+	defer func() { forceGeneric = false }()
+
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lengths := []int{
+		0, 1, 2, 3,
+		shortInt - 1, shortInt, shortInt + 1, shortInt + 2,
+		255, 256, 257, 1<<16 - 1, 1 << 16, 1<<16 + 1,
+	}
+	for i := 0; i < 20; i++ {
+		lengths = append(lengths, rng.Intn(1<<17))
+	}
+	for _, n := range lengths {
+		ad := make([]byte, n)
+		plaintext := make([]byte, rng.Intn(100))
+		rng.Read(ad)
+		rng.Read(plaintext)
+
+		forceGeneric = false
+		want := c.Seal(nil, nonce, plaintext, ad)
+		forceGeneric = true
+		got := c.Seal(nil, nonce, plaintext, ad)
+		if !bytes.Equal(want, got) {
+			t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+		}
+		if _, err := c.Open(nil, nonce, want, ad); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+	}
+}
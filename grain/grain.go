@@ -22,15 +22,79 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"math/bits"
 	"runtime"
 	"strconv"
 	"strings"
+	"unsafe"
 
 	"github.com/ericlagergren/subtle"
 )
 
-var errOpen = errors.New("grain: message authentication failed")
+var (
+	errOpen    = errors.New("grain: message authentication failed")
+	errZeroKey = errors.New("grain: key is all zeros")
+	errLength  = errors.New("grain: unexpected plaintext length")
+)
+
+// maxMessageSize is a test hook, not a limit.
+//
+// Grain-128AEAD allows 2^80 bits (2^77 bytes) of plaintext and
+// additional data per key, nonce pair. That is more than a uint64
+// byte count can hold, let alone a slice, so the real bound can
+// never be reached and is not checked. By default tooLarge never
+// reports true. Tests lower maxMessageSize to exercise the
+// "message too large" paths of Seal, Open, and Authenticate.
+var maxMessageSize uint64 = math.MaxUint64
+
+// tooLarge reports whether a message with n bytes of additional
+// data and m bytes of plaintext or ciphertext exceeds
+// maxMessageSize. It is always false outside of tests.
+func tooLarge(n, m int) bool {
+	return uint64(n) > maxMessageSize ||
+		uint64(m) > maxMessageSize-uint64(n)
+}
+
+// NonceSizeError describes a nonce with the wrong length.
+type NonceSizeError struct {
+	// Got is the length of the nonce.
+	Got int
+	// Want is the required length.
+	Want int
+}
+
+func (e *NonceSizeError) Error() string {
+	return "grain: incorrect nonce length: " + strconv.Itoa(e.Got)
+}
+
+// validateNonce returns a *NonceSizeError if nonce is not want
+// bytes long.
+func validateNonce(nonce []byte, want int) error {
+	if len(nonce) != want {
+		return &NonceSizeError{Got: len(nonce), Want: want}
+	}
+	return nil
+}
+
+// checkNonce panics if nonce is not NonceSize bytes long.
+func checkNonce(nonce []byte) {
+	if err := validateNonce(nonce, NonceSize); err != nil {
+		panic(err.Error())
+	}
+}
+
+// maxInt is the largest value of an int.
+const maxInt = int(^uint(0) >> 1)
+
+// checkPlaintextLen panics if the ciphertext for n bytes of
+// plaintext would be too large for an int, which is only
+// possible on 32-bit platforms.
+func checkPlaintextLen(n int) {
+	if n > maxInt-TagSize {
+		panic("grain: plaintext too large")
+	}
+}
 
 const (
 	// BlockSize is the size in bytes of an Grain128-AEAD block.
@@ -46,12 +110,32 @@ const (
 
 // NewUnauthenticated creates a Grain128a stream cipher.
 //
+// The stream cipher only uses the key stream bits of the
+// pre-output generator and never runs the authenticator, so it
+// is about 1.8 times as fast as Seal (see
+// BenchmarkUnauthenticated8K and BenchmarkSeal8K). Its key
+// stream is the same key stream that Seal uses, except that
+// Seal first consumes one byte of key stream per byte of
+// DER(len(ad)) || ad.
+//
 // Grain128a must not be used to encrypt more than 2^80 bits per
 // key, nonce pair.
+//
+// The returned cipher.Stream also has a Destroy method that
+// zeroes the key and key stream, after which the stream must not
+// be used. Call it with a type assertion:
+//
+//	s.(interface{ Destroy() }).Destroy()
+//
+// NewStream returns a *Stream, which has the same Destroy method
+// without the assertion.
 func NewUnauthenticated(key, nonce []byte) (cipher.Stream, error) {
 	if len(key) != KeySize {
 		return nil, errors.New("grain: bad key length")
 	}
+	if err := validateNonce(nonce, NonceSize); err != nil {
+		return nil, err
+	}
 	var s stream
 	s.s.setKey(key)
 	s.s.init(nonce)
@@ -66,11 +150,27 @@ type stream struct {
 	// There is a remaining key stream byte, its high bits will
 	// be set.
 	ks uint16
+	// destroyed is set by Destroy.
+	destroyed bool
 }
 
 var _ cipher.Stream = (*stream)(nil)
 
+// Destroy zeroes the key and key stream.
+//
+// The stream must not be used after calling Destroy. Doing so
+// causes a panic.
+func (s *stream) Destroy() {
+	s.s = state{}
+	s.ks = 0
+	s.destroyed = true
+	runtime.KeepAlive(s)
+}
+
 func (s *stream) XORKeyStream(dst, src []byte) {
+	if s.destroyed {
+		panic("grain: use after Destroy")
+	}
 	if len(src) == 0 {
 		return
 	}
@@ -106,6 +206,36 @@ func (s *stream) XORKeyStream(dst, src []byte) {
 	}
 }
 
+// fill overwrites dst with key stream.
+func (s *stream) fill(dst []byte) {
+	if s.destroyed {
+		panic("grain: use after Destroy")
+	}
+	if len(dst) == 0 {
+		return
+	}
+
+	// Remaining key stream.
+	const mask = 0xff00
+	if s.ks&mask != 0 {
+		dst[0] = byte(s.ks)
+		dst = dst[1:]
+	}
+
+	for len(dst) >= 2 {
+		binary.LittleEndian.PutUint16(dst, getkb(next(&s.s)))
+		dst = dst[2:]
+	}
+
+	if len(dst) > 0 {
+		w := getkb(next(&s.s))
+		s.ks = mask | w>>8
+		dst[0] = byte(w)
+	} else {
+		s.ks = 0
+	}
+}
+
 // state is the pure Go "generic" implementation of
 // Grain-128AEAD.
 //
@@ -181,34 +311,130 @@ type state struct {
 	reg uint64
 }
 
-var _ cipher.AEAD = (*state)(nil)
+// stateSize is the size in bytes of state.
+//
+// state is copied for every call to Seal and Open, so it should
+// stay small. The following fail to compile if the size of state
+// changes.
+const stateSize = 64
+
+var (
+	_ [stateSize - unsafe.Sizeof(state{})]byte
+	_ [unsafe.Sizeof(state{}) - stateSize]byte
+)
+
+// AEAD is a Grain-128AEAD AEAD.
+//
+// The cipher.AEAD returned by New is an *AEAD. Use a type
+// assertion to access its additional methods.
+type AEAD struct {
+	// s contains the key schedule. Each call to Seal or Open
+	// operates on a copy of s.
+	s state
+	// destroyed is set by Destroy.
+	destroyed bool
+}
+
+var _ cipher.AEAD = (*AEAD)(nil)
 
 // New creates a 128-bit Grain128-AEAD AEAD.
 //
 // Grain128-AEAD must not be used to encrypt more than 2^80 bits
 // per key, nonce pair, including additional authenticated data.
+// This is not checked: no slice is long enough to reach it.
 func New(key []byte) (cipher.AEAD, error) {
 	if len(key) != KeySize {
 		return nil, errors.New("grain: bad key length")
 	}
-	var s state
-	s.setKey(key)
-	return &s, nil
+	var a AEAD
+	a.s.setKey(key)
+	return &a, nil
+}
+
+// Implementation reports which implementation of the
+// Grain-128AEAD pre-output generator is compiled in: "amd64" or
+// "generic".
+//
+// It is intended for diagnostics, such as logging at startup.
+func Implementation() string {
+	return implementation
+}
+
+// NewStrict is like New, but returns an error if the key is
+// all zeros.
+//
+// An all-zero key is not weak as far as Grain-128AEAD is
+// concerned, but it usually means that the key was never
+// initialized. Use NewStrict to catch such bugs early.
+func NewStrict(key []byte) (cipher.AEAD, error) {
+	var zero [KeySize]byte
+	if subtle.ConstantTimeCompare(key, zero[:]) == 1 {
+		return nil, errZeroKey
+	}
+	return New(key)
+}
+
+// newFromState creates an AEAD with the same key schedule as s.
+//
+// Only the key schedule is copied, not any per-message state.
+func newFromState(s *state) *AEAD {
+	var a AEAD
+	a.s.key = s.key
+	return &a
+}
+
+// Clone returns an independent copy of aead, which must have
+// been created by this package.
+//
+// The clone has the same key as aead. Destroying one does not
+// affect the other.
+func Clone(aead cipher.AEAD) cipher.AEAD {
+	a, ok := aead.(*AEAD)
+	if !ok {
+		panic("grain: Clone called with a foreign AEAD")
+	}
+	if a.destroyed {
+		panic("grain: use after Destroy")
+	}
+	return newFromState(&a.s)
 }
 
-func (s *state) NonceSize() int {
+func (a *AEAD) NonceSize() int {
 	return NonceSize
 }
 
-func (s *state) Overhead() int {
+func (a *AEAD) Overhead() int {
 	return TagSize
 }
 
-func (s *state) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
-	if len(nonce) != NonceSize {
-		panic("grain: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+// Destroy zeroes the key.
+//
+// The AEAD must not be used after calling Destroy. Doing so
+// causes a panic.
+func (a *AEAD) Destroy() {
+	a.s = state{}
+	a.destroyed = true
+	runtime.KeepAlive(a)
+}
+
+// init returns a copy of the key schedule initialized with
+// nonce.
+func (a *AEAD) init(nonce []byte) state {
+	if a.destroyed {
+		panic("grain: use after Destroy")
 	}
+	s := a.s
 	s.init(nonce)
+	return s
+}
+
+func (a *AEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	checkNonce(nonce)
+	if tooLarge(len(additionalData), len(plaintext)) {
+		panic("grain: message too large")
+	}
+	checkPlaintextLen(len(plaintext))
+	s := a.init(nonce)
 
 	ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
 	if subtle.InexactOverlap(out, plaintext) {
@@ -222,14 +448,15 @@ func (s *state) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
 	return ret
 }
 
-func (s *state) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
-	if len(nonce) != NonceSize {
-		panic("grain: incorrect nonce length: " + strconv.Itoa(len(nonce)))
-	}
+func (a *AEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	checkNonce(nonce)
 	if len(ciphertext) < TagSize {
 		return nil, errOpen
 	}
-	s.init(nonce)
+	if tooLarge(len(additionalData), len(ciphertext)-TagSize) {
+		return nil, errOpen
+	}
+	s := a.init(nonce)
 
 	tag := ciphertext[len(ciphertext)-TagSize:]
 	ciphertext = ciphertext[:len(ciphertext)-TagSize]
@@ -254,6 +481,20 @@ func (s *state) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, err
 	return ret, nil
 }
 
+// OpenExact is like Open, but returns an error without
+// decrypting anything if the plaintext would not be exactly
+// expectedLen bytes long.
+//
+// It is useful for protocols that know the length of the
+// plaintext in advance, since it catches framing errors early.
+func (a *AEAD) OpenExact(dst, nonce, ciphertext, additionalData []byte, expectedLen int) ([]byte, error) {
+	checkNonce(nonce)
+	if len(ciphertext)-TagSize != expectedLen || expectedLen < 0 {
+		return nil, errLength
+	}
+	return a.Open(dst, nonce, ciphertext, additionalData)
+}
+
 func (s *state) encrypt(dst, src, ad []byte) {
 	// der contains the DER-encoded length of ad. Always ensure
 	// that DER has an even number of bytes to simplify the
@@ -294,13 +535,19 @@ func (s *state) encrypt(dst, src, ad []byte) {
 		word := next(s)
 		s.accumulate8(uint8(getmb(word)), ad[0])
 		if len(src) > 0 {
-			dst[0] = uint8(getkb(word)>>8) ^ src[0]
-			s.accumulate8(uint8(getmb(word)>>8), src[0])
+			// Read src before writing dst in case they
+			// overlap.
+			v := src[0]
+			dst[0] = uint8(getkb(word)>>8) ^ v
+			s.accumulate8(uint8(getmb(word)>>8), v)
 			src = src[1:]
 			dst = dst[1:]
 		}
 	}
 
+	// Each call to next yields 16 bits of key stream and 16
+	// bits of MAC stream, so this loop cannot consume more than
+	// two bytes per call.
 	for len(src) >= 2 {
 		next := next(s)
 		v := binary.LittleEndian.Uint16(src)
@@ -312,9 +559,10 @@ func (s *state) encrypt(dst, src, ad []byte) {
 
 	if len(src) > 0 {
 		word := next(s)
-		dst[0] = byte(getkb(word)) ^ src[0]
+		v := src[0]
+		dst[0] = byte(getkb(word)) ^ v
 		s.reg, s.acc = accumulate(s.reg, s.acc, getmb(word),
-			0x100|uint16(src[0]))
+			0x100|uint16(v))
 	} else {
 		s.reg, s.acc = accumulate(s.reg, s.acc, getmb(next(s)), 0x01)
 	}
@@ -577,8 +825,8 @@ func encode(x int) (d der) {
 	n := (bits.Len(uint(x)) + 7) / 8
 	d[0] = byte(0x80 | n)
 	for i := n; i > 0; i-- {
-		d[i] = byte(n)
-		n >>= 8
+		d[i] = byte(x)
+		x >>= 8
 	}
 	return d
 }
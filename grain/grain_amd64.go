@@ -0,0 +1,6 @@
+//go:build gc && !purego
+// +build gc,!purego
+
+package grain
+
+const implementation = "amd64"
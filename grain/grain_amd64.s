@@ -4,8 +4,8 @@
 
 #include "textflag.h"
 
-// func next(s *state) uint32
-TEXT ·next(SB), NOSPLIT, $0-12
+// func nextAsm(s *state) uint32
+TEXT ·nextAsm(SB), NOSPLIT, $0-12
 	// Load state
 	MOVQ s+0(FP), AX
 
@@ -299,8 +299,8 @@ TEXT ·next(SB), NOSPLIT, $0-12
 	MOVL R9, ret+8(FP)
 	RET
 
-// func accumulate(reg uint64, acc uint64, ms uint16, pt uint16) (reg1 uint64, acc1 uint64)
-TEXT ·accumulate(SB), NOSPLIT, $0-40
+// func accumulateAsm(reg uint64, acc uint64, ms uint16, pt uint16) (reg1 uint64, acc1 uint64)
+TEXT ·accumulateAsm(SB), NOSPLIT, $0-40
 	MOVQ    reg+0(FP), AX
 	MOVQ    acc+8(FP), CX
 	MOVWLZX pt+18(FP), DX
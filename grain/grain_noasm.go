@@ -2,6 +2,8 @@
 
 package grain
 
+const implementation = "generic"
+
 func next(s *state) uint32 {
 	return nextGeneric(s)
 }
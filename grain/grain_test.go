@@ -4,14 +4,21 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/quick"
+
+	"github.com/ericlagergren/lwcrypto/grain/internal/ref"
+	"github.com/ericlagergren/lwcrypto/internal/kat"
 )
 
 func TestKeystream(t *testing.T) {
@@ -76,10 +83,213 @@ func TestAuth(t *testing.T) {
 	}
 }
 
+// TestGetBits tests getmb and getkb against a straightforward
+// bit-by-bit reference for every input.
+//
+// getmb only reads the odd bits and getkb only reads the even
+// bits, so sweeping the 2^16 values of those bits, with the
+// other bits set to random values, covers every input.
+func TestGetBits(t *testing.T) {
+	// spread returns v with bit i moved to bit 2i+shift.
+	spread := func(v uint16, shift uint) uint32 {
+		var x uint32
+		for i := uint(0); i < 16; i++ {
+			x |= uint32(v>>i&1) << (2*i + shift)
+		}
+		return x
+	}
+	// gather returns the bits of x at 2i+shift, LSB first.
+	gather := func(x uint32, shift uint) uint16 {
+		var v uint16
+		for i := uint(0); i < 16; i++ {
+			v |= uint16(x>>(2*i+shift)&1) << i
+		}
+		return v
+	}
+
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	for v := 0; v <= math.MaxUint16; v++ {
+		noise := rng.Uint32()
+
+		x := spread(uint16(v), 1) | noise&0x55555555
+		if want, got := gather(x, 1), getmb(x); want != got {
+			t.Fatalf("getmb(%#08x): expected %#04x, got %#04x", x, want, got)
+		}
+		if got := getmb(x); got != uint16(v) {
+			t.Fatalf("getmb(%#08x): expected %#04x, got %#04x", x, v, got)
+		}
+
+		x = spread(uint16(v), 0) | noise&0xAAAAAAAA
+		if want, got := gather(x, 0), getkb(x); want != got {
+			t.Fatalf("getkb(%#08x): expected %#04x, got %#04x", x, want, got)
+		}
+		if got := getkb(x); got != uint16(v) {
+			t.Fatalf("getkb(%#08x): expected %#04x, got %#04x", x, v, got)
+		}
+	}
+}
+
 func TestVectorsLE(t *testing.T) {
 	testVectors(t, New, filepath.Join("testdata", "little_endian.txt"))
 }
 
+func TestVectorsRef(t *testing.T) {
+	testVectors(t, ref.New, filepath.Join("testdata", "little_endian.txt"))
+}
+
+// TestRef compares Seal and Open against the bit-level reference
+// implementation, including additional data long enough to use
+// the long form of the DER-encoded length.
+func TestRef(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	want, err := ref.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{
+		0, 1, shortInt - 1, shortInt, shortInt + 1, 255, 256, 257,
+		1<<16 - 1, 1 << 16,
+	} {
+		ad := make([]byte, n)
+		plaintext := make([]byte, n%37)
+		rng.Read(ad)
+		rng.Read(plaintext)
+
+		wantCt := want.Seal(nil, nonce, plaintext, ad)
+		gotCt := got.Seal(nil, nonce, plaintext, ad)
+		if !bytes.Equal(wantCt, gotCt) {
+			t.Fatalf("%d: expected %#x, got %#x", n, wantCt, gotCt)
+		}
+		pt, err := got.Open(nil, nonce, wantCt, ad)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("%d: expected %#x, got %#x", n, plaintext, pt)
+		}
+	}
+}
+
+// TestRefEvenPlaintext compares Seal and Open against the
+// reference implementation for even-length plaintexts, including
+// common audio frame sizes.
+//
+// With odd-length additional data, the first plaintext byte
+// shares a key stream word with the last additional data byte,
+// so the remaining plaintext is processed off by one.
+func TestRefEvenPlaintext(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	want, err := ref.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := []int{160, 320, 640, 1280}
+	for n := 0; n <= 64; n += 2 {
+		sizes = append(sizes, n)
+	}
+	for _, n := range sizes {
+		for _, adLen := range []int{0, 1, 2, 13} {
+			plaintext := make([]byte, n)
+			ad := make([]byte, adLen)
+			rng.Read(plaintext)
+			rng.Read(ad)
+
+			wantCt := want.Seal(nil, nonce, plaintext, ad)
+			gotCt := got.Seal(nil, nonce, plaintext, ad)
+			if !bytes.Equal(wantCt, gotCt) {
+				t.Fatalf("%d, %d: expected %#x, got %#x", n, adLen, wantCt, gotCt)
+			}
+			pt, err := got.Open(nil, nonce, wantCt, ad)
+			if err != nil {
+				t.Fatalf("%d, %d: %v", n, adLen, err)
+			}
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("%d, %d: expected %#x, got %#x", n, adLen, plaintext, pt)
+			}
+		}
+	}
+}
+
+// TestRefEmptyPlaintext compares Seal against the reference
+// implementation when the plaintext is empty, in which case the
+// tag is a MAC over the additional data alone.
+//
+// Odd and even lengths exercise different paths for the final
+// byte of additional data and the padding bit, and lengths from
+// shortInt+1 use the long form of the DER-encoded length.
+func TestRefEmptyPlaintext(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	want, err := ref.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{
+		1, 2, 3, 15, 16, 17, shortInt - 1, shortInt, shortInt + 1,
+		shortInt + 2, 200, 255, 256, 257, 1000, 1001,
+	} {
+		ad := make([]byte, n)
+		rng.Read(ad)
+
+		wantTag := want.Seal(nil, nonce, nil, ad)
+		gotTag := got.Seal(nil, nonce, nil, ad)
+		if !bytes.Equal(wantTag, gotTag) {
+			t.Fatalf("%d: expected %#x, got %#x", n, wantTag, gotTag)
+		}
+		if _, err := got.Open(nil, nonce, wantTag, ad); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+
+		d := got.(*AEAD).NewDecryptor(nonce)
+		if err := d.BeginAD(n); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range split(rng, ad) {
+			if err := d.WriteAD(p); err != nil {
+				t.Fatalf("%d: %v", n, err)
+			}
+		}
+		if _, err := d.Write(wantTag); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if _, err := d.Finish(nil); err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+
+		// The tag must depend on every byte of additional data.
+		ad[rng.Intn(n)] ^= 1
+		if _, err := got.Open(nil, nonce, wantTag, ad); err != errOpen {
+			t.Fatalf("%d: expected %v, got %v", n, errOpen, err)
+		}
+	}
+}
+
 func testVectors(t *testing.T, fn func([]byte) (cipher.AEAD, error), path string) {
 	vecs, err := readVecs(path)
 	if err != nil {
@@ -104,6 +314,614 @@ func testVectors(t *testing.T, fn func([]byte) (cipher.AEAD, error), path string
 	}
 }
 
+// TestGenerateKAT tests GenerateKAT against the known answer
+// tests in testdata/little_endian.txt.
+func TestGenerateKAT(t *testing.T) {
+	vecs, err := readVecs(filepath.Join("testdata", "little_endian.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := GenerateKAT(len(vecs))
+	if len(gen) != len(vecs) {
+		t.Fatalf("expected %d vectors, got %d", len(vecs), len(gen))
+	}
+	for i, v := range vecs {
+		got := gen[i]
+		for _, f := range []struct {
+			name      string
+			want, got []byte
+		}{
+			{"Key", v.key, got.Key},
+			{"Nonce", v.nonce, got.Nonce},
+			{"PT", v.pt, got.PT},
+			{"AD", v.ad, got.AD},
+			{"CT", v.ct, got.CT},
+		} {
+			if !bytes.Equal(f.want, f.got) {
+				t.Fatalf("#%d: %s: expected %#x, got %#x", i+1, f.name, f.want, f.got)
+			}
+		}
+	}
+}
+
+// TestLongAD tests additional data long enough to use the long
+// form of the DER-encoded length, which the known answer tests
+// (at most 32 bytes) never reach.
+//
+// No published vectors cover these lengths. The expected values
+// were computed with the bit-level implementation in
+// internal/ref, which is also checked here.
+func TestLongAD(t *testing.T) {
+	key := kat.Incrementing(KeySize)
+	nonce := kat.Incrementing(NonceSize)
+	for _, tc := range []struct {
+		pt, ad int
+		ct     string
+	}{
+		{0, 128, "7C95E9587AE626C3"},
+		{16, 200, "E9B2E4CCFD1ACE205AF09A7B68AA013716FE0D26FF5051C8"},
+		{5, 300, "F54CCAA4F6929C49F5A84EA3F0"},
+	} {
+		want, err := hex.DecodeString(tc.ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pt := kat.Incrementing(tc.pt)
+		ad := kat.Incrementing(tc.ad)
+		for _, fn := range []func([]byte) (cipher.AEAD, error){New, ref.New} {
+			c, err := fn(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := c.Seal(nil, nonce, pt, ad)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x", tc.pt, tc.ad, want, got)
+			}
+			got, err = c.Open(nil, nonce, want, ad)
+			if err != nil {
+				t.Fatalf("(%d, %d): %v", tc.pt, tc.ad, err)
+			}
+			if !bytes.Equal(got, pt) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x", tc.pt, tc.ad, pt, got)
+			}
+		}
+	}
+}
+
+// message is a random input to Seal.
+type message struct {
+	Key, Nonce, Plaintext, AD []byte
+}
+
+var _ quick.Generator = message{}
+
+func (message) Generate(rng *rand.Rand, size int) reflect.Value {
+	m := message{
+		Key:       make([]byte, KeySize),
+		Nonce:     make([]byte, NonceSize),
+		Plaintext: make([]byte, rng.Intn(4*size+1)),
+		// Make sure to exercise both the short and long DER
+		// length encodings.
+		AD: make([]byte, rng.Intn(2*(shortInt+1)+1)),
+	}
+	rng.Read(m.Key)
+	rng.Read(m.Nonce)
+	rng.Read(m.Plaintext)
+	rng.Read(m.AD)
+	return reflect.ValueOf(m)
+}
+
+// TestAuthenticate tests that Authenticate returns the same tag
+// as sealing an empty plaintext.
+func TestAuthenticate(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	rng.Read(key)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+	// Include the boundary between the short and long DER
+	// encodings of the length.
+	for n := 0; n <= 64; n++ {
+		testAuthenticate(t, rng, aead, n)
+	}
+	for _, n := range []int{127, 128, 255, 256, 4096} {
+		testAuthenticate(t, rng, aead, n)
+	}
+}
+
+func testAuthenticate(t *testing.T, rng *rand.Rand, aead *AEAD, n int) {
+	nonce := make([]byte, NonceSize)
+	rng.Read(nonce)
+	ad := make([]byte, n)
+	rng.Read(ad)
+
+	want := aead.Seal(nil, nonce, nil, ad)
+	got := aead.Authenticate(nonce, ad)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("#%d: expected %#x, got %#x", n, want, got)
+	}
+	if _, err := aead.Open(nil, nonce, got, ad); err != nil {
+		t.Fatalf("#%d: %v", n, err)
+	}
+}
+
+// TestChecked tests that SealChecked and OpenChecked return
+// errors where Seal and Open panic.
+func TestChecked(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 1
+	nonce := make([]byte, NonceSize)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	if _, err := aead.SealChecked(nil, nonce[1:], nil, nil); err == nil {
+		t.Fatal("expected an error for a short nonce")
+	}
+	if _, err := aead.OpenChecked(nil, nonce[1:], make([]byte, TagSize), nil); err == nil {
+		t.Fatal("expected an error for a short nonce")
+	}
+
+	buf := make([]byte, 64+TagSize)
+	plaintext := buf[1:33]
+	if _, err := aead.SealChecked(buf[:0], nonce, plaintext, nil); err != ErrBufferOverlap {
+		t.Fatalf("expected %v, got %v", ErrBufferOverlap, err)
+	}
+	mustPanic(t, "grain: invalid buffer overlap", func() {
+		aead.Seal(buf[:0], nonce, plaintext, nil)
+	})
+
+	// In-place operation is still allowed.
+	ciphertext, err := aead.SealChecked(plaintext[:0], nonce, plaintext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := aead.OpenChecked(buf[:0], nonce, ciphertext, nil); err != ErrBufferOverlap {
+		t.Fatalf("expected %v, got %v", ErrBufferOverlap, err)
+	}
+	mustPanic(t, "grain: invalid buffer overlap", func() {
+		aead.Open(buf[:0], nonce, ciphertext, nil)
+	})
+	got, err := aead.OpenChecked(ciphertext[:0], nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, make([]byte, len(plaintext))) {
+		t.Fatalf("expected zeros, got %#x", got)
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	f := func(m message) bool {
+		aead, err := New(m.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct := aead.Seal(nil, m.Nonce, m.Plaintext, m.AD)
+		pt, err := aead.Open(nil, m.Nonce, ct, m.AD)
+		return err == nil && bytes.Equal(pt, m.Plaintext)
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	key := make([]byte, KeySize)
+	if _, err := NewStrict(key); err != errZeroKey {
+		t.Fatalf("expected %v, got %v", errZeroKey, err)
+	}
+	if _, err := New(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStrict(key[:KeySize-1]); err == nil || err == errZeroKey {
+		t.Fatalf("expected a key length error, got %v", err)
+	}
+
+	rand.Read(key)
+	if _, err := NewStrict(key); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenExact(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	plaintext := []byte("hello, world!")
+	ciphertext := aead.Seal(nil, nonce, plaintext, nil)
+	got, err := aead.OpenExact(nil, nonce, ciphertext, nil, len(plaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("expected %#x, got %#x", plaintext, got)
+	}
+	for _, n := range []int{-1, 0, len(plaintext) - 1, len(plaintext) + 1} {
+		if _, err := aead.OpenExact(nil, nonce, ciphertext, nil, n); err != errLength {
+			t.Fatalf("%d: expected %v, got %v", n, errLength, err)
+		}
+	}
+	if _, err := aead.OpenExact(nil, nonce, ciphertext[:TagSize-1], nil, -1); err != errLength {
+		t.Fatalf("expected %v, got %v", errLength, err)
+	}
+
+	// A length match does not skip authentication.
+	ciphertext[0] ^= 1
+	if _, err := aead.OpenExact(nil, nonce, ciphertext, nil, len(plaintext)); err != errOpen {
+		t.Fatalf("expected %v, got %v", errOpen, err)
+	}
+}
+
+func TestCheckPlaintextLen(t *testing.T) {
+	// Plaintexts this large cannot be allocated, so test the
+	// check directly.
+	checkPlaintextLen(0)
+	checkPlaintextLen(maxInt - TagSize)
+	mustPanic(t, "grain: plaintext too large", func() {
+		checkPlaintextLen(maxInt - TagSize + 1)
+	})
+	mustPanic(t, "grain: plaintext too large", func() {
+		checkPlaintextLen(maxInt)
+	})
+}
+
+func TestNonceSize(t *testing.T) {
+	key := make([]byte, KeySize)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	for _, n := range []int{0, NonceSize - 1, NonceSize + 1} {
+		nonce := make([]byte, n)
+		want := (&NonceSizeError{Got: n, Want: NonceSize}).Error()
+		for name, fn := range map[string]func(){
+			"Seal": func() { aead.Seal(nil, nonce, nil, nil) },
+			"Open": func() { aead.Open(nil, nonce, make([]byte, TagSize), nil) },
+			"OpenExact": func() {
+				aead.OpenExact(nil, nonce, make([]byte, TagSize), nil, 0)
+			},
+			"NewDecryptor": func() { aead.NewDecryptor(nonce) },
+		} {
+			t.Run(name, func(t *testing.T) {
+				mustPanic(t, want, fn)
+			})
+		}
+
+		_, err := NewUnauthenticated(key, nonce)
+		e, ok := err.(*NonceSizeError)
+		if !ok {
+			t.Fatalf("%d: expected *NonceSizeError, got %T", n, err)
+		}
+		if e.Got != n || e.Want != NonceSize || e.Error() != want {
+			t.Fatalf("%d: unexpected error: %+v", n, e)
+		}
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := c.Seal(nil, nonce, nil, nil)
+	c.(*AEAD).Destroy()
+
+	mustPanic(t, "grain: use after Destroy", func() {
+		c.Seal(nil, nonce, nil, nil)
+	})
+	mustPanic(t, "grain: use after Destroy", func() {
+		c.Open(nil, nonce, ciphertext, nil)
+	})
+
+	s, err := NewUnauthenticated(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Destroy is only reachable through an interface
+	// assertion, as documented on NewUnauthenticated.
+	d, ok := s.(interface{ Destroy() })
+	if !ok {
+		t.Fatal("stream does not implement Destroy")
+	}
+	d.Destroy()
+	mustPanic(t, "grain: use after Destroy", func() {
+		s.XORKeyStream(make([]byte, 1), make([]byte, 1))
+	})
+}
+
+// TestUnauthenticated tests that the unauthenticated stream
+// cipher produces the same key stream as Seal and that it never
+// touches the authenticator.
+func TestUnauthenticated(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+
+	aead, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, adLen := range []int{0, 1, 2, 5, 127, 128, 200, 256} {
+		for _, n := range []int{0, 1, 2, 3, 16, 33} {
+			ad := make([]byte, adLen)
+			rng.Read(ad)
+			plaintext := make([]byte, n)
+			rng.Read(plaintext)
+			ciphertext := aead.Seal(nil, nonce, plaintext, ad)
+
+			// Seal uses the key stream for DER(len(ad)) || ad
+			// before the plaintext.
+			var buf der
+			skip := len(derLen(&buf, adLen)) + adLen
+
+			s, err := NewUnauthenticated(key, nonce)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := s.(*stream).s
+			ks := make([]byte, skip+n)
+			s.XORKeyStream(ks, ks)
+			if !equalAuthState(&before, &s.(*stream).s) {
+				t.Fatalf("(%d, %d): authenticator was modified", adLen, n)
+			}
+
+			got := make([]byte, n)
+			for i := range got {
+				got[i] = plaintext[i] ^ ks[skip+i]
+			}
+			want := ciphertext[:n]
+			if !bytes.Equal(got, want) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x", adLen, n, want, got)
+			}
+		}
+	}
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	defer func(n uint64) {
+		maxMessageSize = n
+	}(maxMessageSize)
+	maxMessageSize = 100
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		ad, pt int
+		ok     bool
+	}{
+		{0, 0, true},
+		{100, 0, true},
+		{0, 100, true},
+		{50, 50, true},
+		{101, 0, false},
+		{0, 101, false},
+		{51, 50, false},
+	} {
+		ad := make([]byte, tc.ad)
+		pt := make([]byte, tc.pt)
+		if !tc.ok {
+			mustPanic(t, "grain: message too large", func() {
+				c.Seal(nil, nonce, pt, ad)
+			})
+			maxMessageSize = math.MaxUint64
+			ct := c.Seal(nil, nonce, pt, ad)
+			maxMessageSize = 100
+			if _, err := c.Open(nil, nonce, ct, ad); err == nil {
+				t.Fatalf("(%d, %d): expected an error", tc.ad, tc.pt)
+			}
+			continue
+		}
+		ct := c.Seal(nil, nonce, pt, ad)
+		if _, err := c.Open(nil, nonce, ct, ad); err != nil {
+			t.Fatalf("(%d, %d): %v", tc.ad, tc.pt, err)
+		}
+	}
+}
+
+// TestInPlace tests that Seal and Open work when dst exactly
+// overlaps the input.
+func TestInPlace(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	aead, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for adLen := 0; adLen < 4; adLen++ {
+		for ptLen := 0; ptLen < 40; ptLen++ {
+			ad := make([]byte, adLen)
+			plaintext := make([]byte, ptLen)
+			rand.Read(ad)
+			rand.Read(plaintext)
+			want := aead.Seal(nil, nonce, plaintext, ad)
+
+			buf := make([]byte, ptLen, ptLen+TagSize)
+			copy(buf, plaintext)
+			got := aead.Seal(buf[:0], nonce, buf, ad)
+			if !bytes.Equal(want, got) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x",
+					adLen, ptLen, want, got)
+			}
+
+			pt, err := aead.Open(got[:0], nonce, got, ad)
+			if err != nil {
+				t.Fatalf("(%d, %d): %v", adLen, ptLen, err)
+			}
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x",
+					adLen, ptLen, plaintext, pt)
+			}
+		}
+	}
+}
+
+// TestAppend tests Seal and Open with a non-empty dst, with and
+// without enough capacity.
+func TestAppend(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	aead, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := []byte("existing data")
+	ad := make([]byte, 13)
+	rand.Read(ad)
+	for ptLen := 0; ptLen < 40; ptLen++ {
+		plaintext := make([]byte, ptLen)
+		rand.Read(plaintext)
+		want := aead.Seal(nil, nonce, plaintext, ad)
+
+		n := len(prefix) + ptLen + TagSize
+		for _, c := range []int{len(prefix), n - 1, n, n + 8} {
+			dst := make([]byte, len(prefix), c)
+			copy(dst, prefix)
+			got := aead.Seal(dst, nonce, plaintext, ad)
+			if !bytes.Equal(dst, prefix) || !bytes.Equal(got[:len(prefix)], prefix) {
+				t.Fatalf("(%d, %d): prefix modified", ptLen, c)
+			}
+			if !bytes.Equal(got[len(prefix):], want) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x",
+					ptLen, c, want, got[len(prefix):])
+			}
+
+			dst = make([]byte, len(prefix), c)
+			copy(dst, prefix)
+			pt, err := aead.Open(dst, nonce, want, ad)
+			if err != nil {
+				t.Fatalf("(%d, %d): %v", ptLen, c, err)
+			}
+			if !bytes.Equal(pt[:len(prefix)], prefix) {
+				t.Fatalf("(%d, %d): prefix modified", ptLen, c)
+			}
+			if !bytes.Equal(pt[len(prefix):], plaintext) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x",
+					ptLen, c, plaintext, pt[len(prefix):])
+			}
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	plaintext := make([]byte, 33)
+	ad := make([]byte, 130)
+	rand.Read(key)
+	rand.Read(nonce)
+	rand.Read(plaintext)
+	rand.Read(ad)
+
+	orig, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := Clone(orig)
+	if clone == orig {
+		t.Fatal("Clone returned the same AEAD")
+	}
+	if !sameKey(orig.(*AEAD), clone.(*AEAD)) {
+		t.Fatal("Clone has a different key")
+	}
+
+	want := orig.Seal(nil, nonce, plaintext, ad)
+	got := clone.Seal(nil, nonce, plaintext, ad)
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expected %#x, got %#x", want, got)
+	}
+
+	orig.(*AEAD).Destroy()
+	got = clone.Seal(nil, nonce, plaintext, ad)
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expected %#x, got %#x", want, got)
+	}
+	mustPanic(t, "grain: use after Destroy", func() {
+		Clone(orig)
+	})
+}
+
+// sameKey reports, in constant time, whether a and b have the
+// same key.
+//
+// Tests should use it instead of comparing keys directly.
+func sameKey(a, b *AEAD) bool {
+	var x, y [KeySize]byte
+	for i := range a.s.key {
+		binary.LittleEndian.PutUint32(x[4*i:], a.s.key[i])
+		binary.LittleEndian.PutUint32(y[4*i:], b.s.key[i])
+	}
+	return subtle.ConstantTimeCompare(x[:], y[:]) == 1
+}
+
+func TestSameKey(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	a, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameKey(a.(*AEAD), Clone(a).(*AEAD)) {
+		t.Fatal("expected the same key")
+	}
+	for i := range key {
+		other := append([]byte(nil), key...)
+		other[i] ^= 0x80
+		b, err := New(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sameKey(a.(*AEAD), b.(*AEAD)) {
+			t.Fatalf("#%d: expected different keys", i)
+		}
+	}
+}
+
+func mustPanic(t *testing.T, msg string, fn func()) {
+	t.Helper()
+
+	defer func() {
+		t.Helper()
+
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected panic %q", msg)
+		}
+		if r != msg {
+			t.Fatalf("expected panic %q, got %v", msg, r)
+		}
+	}()
+	fn()
+}
+
 var Sink32 uint32
 
 func BenchmarkKeystream(b *testing.B) {
@@ -175,6 +993,24 @@ func benchmarkAuth(b *testing.B, fn func(uint64, uint64, uint16, uint16) (uint64
 	}
 }
 
+// BenchmarkSeal320 and BenchmarkSeal640 are common audio frame
+// sizes, such as 20 ms and 40 ms of 8 kHz 16-bit samples.
+func BenchmarkSeal320(b *testing.B) {
+	benchmarkSeal(b, New, make([]byte, 320))
+}
+
+func BenchmarkOpen320(b *testing.B) {
+	benchmarkOpen(b, New, make([]byte, 320))
+}
+
+func BenchmarkSeal640(b *testing.B) {
+	benchmarkSeal(b, New, make([]byte, 640))
+}
+
+func BenchmarkOpen640(b *testing.B) {
+	benchmarkOpen(b, New, make([]byte, 640))
+}
+
 func BenchmarkSeal1K(b *testing.B) {
 	benchmarkSeal(b, New, make([]byte, 1024))
 }
@@ -191,6 +1027,73 @@ func BenchmarkOpen8K(b *testing.B) {
 	benchmarkOpen(b, New, make([]byte, 8*1024))
 }
 
+func BenchmarkUnauthenticated1K(b *testing.B) {
+	benchmarkUnauthenticated(b, make([]byte, 1024))
+}
+
+func BenchmarkUnauthenticated8K(b *testing.B) {
+	benchmarkUnauthenticated(b, make([]byte, 8*1024))
+}
+
+// benchmarkUnauthenticated measures the key stream without the
+// authenticator. Compare with benchmarkSeal.
+func benchmarkUnauthenticated(b *testing.B, buf []byte) {
+	b.SetBytes(int64(len(buf)))
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s, err := NewUnauthenticated(key, nonce)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.XORKeyStream(buf, buf)
+	}
+}
+
+func BenchmarkAuthenticate4K(b *testing.B) {
+	benchmarkAuthenticate(b, New, make([]byte, 4*1024))
+}
+
+// BenchmarkSealAD4K is Seal with only additional data. Compare
+// with BenchmarkAuthenticate4K.
+func BenchmarkSealAD4K(b *testing.B) {
+	b.SetBytes(4 * 1024)
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	ad := make([]byte, 4*1024)
+	aead, err := New(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var out []byte
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = aead.Seal(out[:0], nonce, nil, ad)
+	}
+}
+
+func benchmarkAuthenticate(b *testing.B, fn func([]byte) (cipher.AEAD, error), ad []byte) {
+	b.SetBytes(int64(len(ad)))
+
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := fn(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	aead := c.(*AEAD)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		aead.Authenticate(nonce, ad)
+	}
+}
+
 func benchmarkSeal(b *testing.B, fn func([]byte) (cipher.AEAD, error), buf []byte) {
 	b.SetBytes(int64(len(buf)))
 
@@ -292,3 +1195,100 @@ func readVecs(path string) ([]vector, error) {
 	}
 	return vecs, nil
 }
+
+func TestMarshalBinary(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	plaintext := []byte("hello, world!")
+	ad := []byte("additional data")
+
+	aead, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := aead.(*AEAD).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte{marshalVersion, algGrain128AEAD}, key...)
+	if !bytes.Equal(blob, want) {
+		t.Fatalf("expected %#x, got %#x", want, blob)
+	}
+
+	var b AEAD
+	if err := b.UnmarshalBinary(blob); err != nil {
+		t.Fatal(err)
+	}
+	got := b.Seal(nil, nonce, plaintext, ad)
+	if want := aead.Seal(nil, nonce, plaintext, ad); !bytes.Equal(got, want) {
+		t.Fatalf("expected %#x, got %#x", want, got)
+	}
+
+	for i, bad := range [][]byte{
+		nil,
+		blob[:len(blob)-1],
+		append(blob[:len(blob):len(blob)], 0),
+		append([]byte{marshalVersion + 1}, blob[1:]...),
+		append([]byte{marshalVersion, 1}, blob[2:]...),
+	} {
+		if err := b.UnmarshalBinary(bad); err == nil {
+			t.Fatalf("#%d: expected an error", i)
+		}
+	}
+}
+
+func TestKeystreamSelfTest(t *testing.T) {
+	if err := KeystreamSelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The prefix must agree with Seal, which uses one byte of
+	// key stream for the empty additional data.
+	aead, err := New(selfTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := aead.Seal(nil, selfTestNonce, make([]byte, len(selfTestPrefix)-1), nil)
+	if want := selfTestPrefix[1:]; !bytes.Equal(ct[:len(want)], want) {
+		t.Fatalf("expected %#x, got %#x", want, ct[:len(want)])
+	}
+
+	// A stuck shift register.
+	var s stream
+	s.s.setKey(selfTestKey)
+	s.s.init(selfTestNonce)
+	s.s.lfsr = lfsr{}
+	s.s.nfsr = lfsr{}
+	ks := make([]byte, selfTestSize)
+	s.XORKeyStream(ks, ks)
+	if err := checkKeystream(ks); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The correct prefix followed by zeros.
+	good := make([]byte, selfTestSize)
+	copy(good, selfTestPrefix)
+	for i, tc := range []struct {
+		fn   func(ks []byte)
+		want error
+	}{
+		{func(ks []byte) { ks[0] ^= 1 }, errSelfTestPrefix},
+		{func(ks []byte) {}, errSelfTestBalance},
+		{func(ks []byte) {
+			for i := len(selfTestPrefix); i < len(ks); i++ {
+				ks[i] = 0x55
+			}
+			for i := len(ks) / 2; i < len(ks)/2+4; i++ {
+				ks[i] = 0
+			}
+		}, errSelfTestRun},
+	} {
+		ks := append([]byte(nil), good...)
+		tc.fn(ks)
+		if err := checkKeystream(ks); err != tc.want {
+			t.Fatalf("#%d: expected %v, got %v", i, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package ref implements a slow, bit-level reference
+// implementation of Grain-128AEAD.
+//
+// It follows the specification directly: each register is an
+// array of bits, each clock computes one bit of pre-output, and
+// the message is authenticated one bit at a time. It is only
+// intended for differential testing.
+//
+// Bits are numbered starting from the least significant bit of
+// the first byte, as in the specification's little-endian test
+// vectors.
+//
+// References:
+//
+//	[grain]: https://grain-128aead.github.io/
+package ref
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"github.com/ericlagergren/subtle"
+)
+
+const (
+	keySize   = 16
+	nonceSize = 12
+	tagSize   = 8
+)
+
+type aead struct {
+	key []byte
+}
+
+// New creates a Grain-128AEAD AEAD.
+func New(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid key size: %d", len(key))
+	}
+	return &aead{key: key}, nil
+}
+
+func (a *aead) NonceSize() int {
+	return nonceSize
+}
+
+func (a *aead) Overhead() int {
+	return tagSize
+}
+
+func (a *aead) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != nonceSize {
+		panic("invalid nonce size")
+	}
+	var g grain
+	g.init(a.key, nonce)
+	g.authenticate(der(len(additionalData)))
+	g.authenticate(additionalData)
+	ret, out := subtle.SliceForAppend(dst, len(plaintext)+tagSize)
+	g.encrypt(out, plaintext)
+	g.tag(out[len(plaintext):])
+	return ret
+}
+
+func (a *aead) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != nonceSize {
+		panic("invalid nonce size")
+	}
+	if len(ciphertext) < tagSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	tag := ciphertext[len(ciphertext)-tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-tagSize]
+
+	var g grain
+	g.init(a.key, nonce)
+	g.authenticate(der(len(additionalData)))
+	g.authenticate(additionalData)
+	ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+	g.decrypt(out, ciphertext)
+	expectedTag := make([]byte, tagSize)
+	g.tag(expectedTag)
+	if subtle.ConstantTimeCompare(expectedTag, tag) != 1 {
+		return nil, errors.New("authentication failed")
+	}
+	return ret, nil
+}
+
+// der returns the DER encoding of the length n.
+func der(n int) []byte {
+	if n < 128 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// bit returns the ith bit of p.
+func bit(p []byte, i int) uint8 {
+	return (p[i/8] >> (i % 8)) & 1
+}
+
+type grain struct {
+	// s is the LFSR.
+	s [128]uint8
+	// b is the NFSR.
+	b [128]uint8
+	// acc is the accumulator.
+	acc [64]uint8
+	// reg is the shift register.
+	reg [64]uint8
+}
+
+// preOutput returns the pre-output bit y for the current state.
+//
+//	y = h(x) + s_93 + sum_{j in A} b_j
+//
+// where A = {2, 15, 36, 45, 64, 73, 89} and
+//
+//	h(x) = x0x1 + x2x3 + x4x5 + x6x7 + x0x4x8
+//
+// with (x0, ..., x8) = (b_12, s_8, s_13, s_20, b_95, s_42,
+// s_60, s_79, s_94).
+func (g *grain) preOutput() uint8 {
+	s, b := &g.s, &g.b
+	h := b[12]&s[8] ^ s[13]&s[20] ^ b[95]&s[42] ^ s[60]&s[79] ^ b[12]&b[95]&s[94]
+	return h ^ s[93] ^ b[2] ^ b[15] ^ b[36] ^ b[45] ^ b[64] ^ b[73] ^ b[89]
+}
+
+// clock clocks the registers once and returns the pre-output
+// bit. fs and fb are added to the new LFSR and NFSR bits,
+// respectively.
+func (g *grain) clock(fs, fb func(y uint8) uint8) uint8 {
+	s, b := &g.s, &g.b
+	y := g.preOutput()
+
+	// s_127 = s_0 + s_7 + s_38 + s_70 + s_81 + s_96
+	l := s[0] ^ s[7] ^ s[38] ^ s[70] ^ s[81] ^ s[96]
+
+	// b_127 = s_0 + b_0 + b_26 + b_56 + b_91 + b_96
+	//       + b_3b_67 + b_11b_13 + b_17b_18 + b_27b_59
+	//       + b_40b_48 + b_61b_65 + b_68b_84
+	//       + b_22b_24b_25 + b_70b_78b_82 + b_88b_92b_93b_95
+	f := s[0] ^ b[0] ^ b[26] ^ b[56] ^ b[91] ^ b[96] ^
+		b[3]&b[67] ^ b[11]&b[13] ^ b[17]&b[18] ^ b[27]&b[59] ^
+		b[40]&b[48] ^ b[61]&b[65] ^ b[68]&b[84] ^
+		b[22]&b[24]&b[25] ^ b[70]&b[78]&b[82] ^
+		b[88]&b[92]&b[93]&b[95]
+
+	copy(s[:], s[1:])
+	copy(b[:], b[1:])
+	s[127] = l ^ fs(y)
+	b[127] = f ^ fb(y)
+	return y
+}
+
+func none(uint8) uint8 { return 0 }
+
+// next clocks the registers once without feedback and returns
+// the pre-output bit.
+func (g *grain) next() uint8 {
+	return g.clock(none, none)
+}
+
+// init initializes the pre-output generator and the
+// authenticator generator.
+func (g *grain) init(key, nonce []byte) {
+	for i := range g.b {
+		g.b[i] = bit(key, i)
+	}
+	for i := 0; i < 96; i++ {
+		g.s[i] = bit(nonce, i)
+	}
+	for i := 96; i < 127; i++ {
+		g.s[i] = 1
+	}
+	g.s[127] = 0
+
+	// The pre-output is fed back into both registers for the
+	// first 256 clocks.
+	fb := func(y uint8) uint8 { return y }
+	for i := 0; i < 256; i++ {
+		g.clock(fb, fb)
+	}
+
+	// The next 128 bits of pre-output initialize the
+	// accumulator and then the register while the key is
+	// added to the LFSR.
+	for i := 0; i < 64; i++ {
+		k := bit(key, i)
+		g.acc[i] = g.clock(func(uint8) uint8 { return k }, none)
+	}
+	for i := 0; i < 64; i++ {
+		k := bit(key, 64+i)
+		g.reg[i] = g.clock(func(uint8) uint8 { return k }, none)
+	}
+}
+
+// update encrypts and authenticates the message bit m and
+// returns the key stream bit.
+//
+// Each message bit uses two bits of pre-output. The first is
+// the key stream bit z and the second is shifted into the
+// register.
+func (g *grain) update(m uint8) (z uint8) {
+	z = g.next()
+	g.accumulate(m, g.next())
+	return z
+}
+
+// accumulate adds the register to the accumulator if m is set,
+// then shifts r into the register.
+func (g *grain) accumulate(m, r uint8) {
+	if m == 1 {
+		for i := range g.acc {
+			g.acc[i] ^= g.reg[i]
+		}
+	}
+	copy(g.reg[:], g.reg[1:])
+	g.reg[63] = r
+}
+
+// authenticate authenticates p without encrypting it.
+func (g *grain) authenticate(p []byte) {
+	for i := 0; i < 8*len(p); i++ {
+		g.update(bit(p, i))
+	}
+}
+
+// encrypt encrypts and authenticates src.
+func (g *grain) encrypt(dst, src []byte) {
+	for i := range src {
+		var c byte
+		for j := 0; j < 8; j++ {
+			m := bit(src, 8*i+j)
+			c |= (m ^ g.update(m)) << j
+		}
+		dst[i] = c
+	}
+}
+
+// decrypt decrypts and authenticates src.
+func (g *grain) decrypt(dst, src []byte) {
+	for i := range src {
+		var m byte
+		for j := 0; j < 8; j++ {
+			z := g.next()
+			b := bit(src, 8*i+j) ^ z
+			g.accumulate(b, g.next())
+			m |= b << j
+		}
+		dst[i] = m
+	}
+}
+
+// tag pads the message with a single one bit and writes the
+// accumulator to dst.
+func (g *grain) tag(dst []byte) {
+	g.accumulate(1, 0)
+	for i := range dst {
+		var t byte
+		for j := 0; j < 8; j++ {
+			t |= g.acc[8*i+j] << j
+		}
+		dst[i] = t
+	}
+}
+
+// Trace returns the accumulator and register after
+// initialization, after each byte of DER(len(ad)) || ad ||
+// plaintext, and after the padding bit.
+//
+// Each state is packed into a uint64 whose ith bit is the ith
+// bit of the accumulator or register.
+func Trace(key, nonce, plaintext, additionalData []byte) (acc, reg []uint64) {
+	var g grain
+	g.init(key, nonce)
+	snapshot := func() {
+		acc = append(acc, pack(&g.acc))
+		reg = append(reg, pack(&g.reg))
+	}
+	snapshot()
+	msg := append(der(len(additionalData)), additionalData...)
+	for i := range msg {
+		g.authenticate(msg[i : i+1])
+		snapshot()
+	}
+	c := make([]byte, 1)
+	for i := range plaintext {
+		g.encrypt(c, plaintext[i:i+1])
+		snapshot()
+	}
+	g.tag(nil)
+	snapshot()
+	return acc, reg
+}
+
+// pack packs 64 bits into a uint64, least significant bit
+// first.
+func pack(p *[64]uint8) uint64 {
+	var v uint64
+	for i, b := range p {
+		v |= uint64(b) << i
+	}
+	return v
+}
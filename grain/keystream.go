@@ -0,0 +1,78 @@
+package grain
+
+import "crypto/cipher"
+
+// Stream is a Grain128a stream cipher that also exposes the key
+// stream as 32-bit words.
+//
+// Like NewUnauthenticated, it provides no authentication and
+// must not be used to produce more than 2^80 bits per key, nonce
+// pair.
+type Stream struct {
+	s stream
+}
+
+var _ cipher.Stream = (*Stream)(nil)
+
+// NewStream creates a Stream.
+func NewStream(key, nonce []byte) (*Stream, error) {
+	s, err := NewUnauthenticated(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{s: *s.(*stream)}, nil
+}
+
+// XORKeyStream XORs each byte in src with a byte from the key
+// stream. See cipher.Stream.
+func (s *Stream) XORKeyStream(dst, src []byte) {
+	s.s.XORKeyStream(dst, src)
+}
+
+// FillKeystream overwrites dst with the next len(dst) bytes of
+// key stream.
+//
+// It is equivalent to XORKeyStream over zeros, but does not read
+// dst and never allocates. If len(dst) is odd, the unused half
+// of the last key stream word is kept for the next call, so
+// successive calls produce one continuous key stream.
+//
+// FillKeystream, NextWord, and XORKeyStream can be mixed freely.
+func (s *Stream) FillKeystream(dst []byte) {
+	s.s.fill(dst)
+}
+
+// NextWord returns the next 32 bits of key stream.
+//
+// The key stream is the even bits of the pre-output, as in
+// Grain-128AEAD. Bit i of the word, counting from the least
+// significant bit, is key stream bit i. Equivalently, the word
+// is the next four bytes of key stream in little-endian order,
+// which are the same bytes XORKeyStream would use.
+//
+// NextWord and XORKeyStream can be mixed freely.
+func (s *Stream) NextWord() uint32 {
+	if s.s.destroyed {
+		panic("grain: use after Destroy")
+	}
+	const mask = 0xff00
+	if s.s.ks&mask != 0 {
+		// One byte of the previous 16 bits remains.
+		w0 := uint32(byte(s.s.ks))
+		w1 := uint32(getkb(next(&s.s.s)))
+		w2 := getkb(next(&s.s.s))
+		s.s.ks = mask | w2>>8
+		return w0 | w1<<8 | uint32(byte(w2))<<24
+	}
+	w0 := uint32(getkb(next(&s.s.s)))
+	w1 := uint32(getkb(next(&s.s.s)))
+	return w0 | w1<<16
+}
+
+// Destroy zeroes the key and key stream.
+//
+// The Stream must not be used after calling Destroy. Doing so
+// causes a panic.
+func (s *Stream) Destroy() {
+	s.s.Destroy()
+}
@@ -0,0 +1,120 @@
+package grain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+func TestStreamNextWord(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+
+	const n = 1024
+	ref, err := NewUnauthenticated(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, n)
+	ref.XORKeyStream(want, want)
+
+	// Only words.
+	s, err := NewStream(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, n)
+	for i := 0; i < n; i += 4 {
+		binary.LittleEndian.PutUint32(got[i:], s.NextWord())
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("expected %#x, got %#x", want, got)
+	}
+
+	// Words mixed with odd numbers of bytes.
+	s, err = NewStream(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = got[:0]
+	for len(got) < n-8 {
+		if rng.Intn(2) == 0 {
+			var b [4]byte
+			binary.LittleEndian.PutUint32(b[:], s.NextWord())
+			got = append(got, b[:]...)
+		} else {
+			b := make([]byte, rng.Intn(4))
+			s.XORKeyStream(b, b)
+			got = append(got, b...)
+		}
+	}
+	if !bytes.Equal(got, want[:len(got)]) {
+		t.Fatalf("expected %#x, got %#x", want[:len(got)], got)
+	}
+
+	s.Destroy()
+	mustPanic(t, "grain: use after Destroy", func() {
+		s.NextWord()
+	})
+}
+
+func TestStreamFillKeystream(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+
+	const n = 1024
+	ref, err := NewUnauthenticated(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, n)
+	ref.XORKeyStream(want, want)
+
+	// Randomly sized calls, mixed with the other forms, produce
+	// one continuous key stream regardless of what dst held.
+	s, err := NewStream(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []byte
+	for len(got) < n-16 {
+		switch rng.Intn(4) {
+		case 0:
+			var b [4]byte
+			binary.LittleEndian.PutUint32(b[:], s.NextWord())
+			got = append(got, b[:]...)
+		case 1:
+			b := make([]byte, rng.Intn(5))
+			s.XORKeyStream(b, b)
+			got = append(got, b...)
+		default:
+			b := make([]byte, rng.Intn(16))
+			rng.Read(b)
+			s.FillKeystream(b)
+			got = append(got, b...)
+		}
+	}
+	if !bytes.Equal(got, want[:len(got)]) {
+		t.Fatalf("expected %#x, got %#x", want[:len(got)], got)
+	}
+
+	buf := make([]byte, 7)
+	allocs := testing.AllocsPerRun(100, func() {
+		s.FillKeystream(buf)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected zero allocations, got %v", allocs)
+	}
+
+	s.Destroy()
+	mustPanic(t, "grain: use after Destroy", func() {
+		s.FillKeystream(buf)
+	})
+}
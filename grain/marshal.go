@@ -0,0 +1,63 @@
+package grain
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// marshalVersion is the version of the key blob format.
+	marshalVersion = 1
+	// marshalSize is the size in bytes of a key blob.
+	marshalSize = 2 + KeySize
+)
+
+// algGrain128AEAD identifies Grain-128AEAD in key blobs.
+//
+// It is distinct from those used by package ascon.
+const algGrain128AEAD = 3
+
+var (
+	_ encoding.BinaryMarshaler   = (*AEAD)(nil)
+	_ encoding.BinaryUnmarshaler = (*AEAD)(nil)
+)
+
+// MarshalBinary encodes the key as
+//
+//	version || algorithm || key
+//
+// where version and algorithm are one byte each. algorithm is 3
+// for Grain-128AEAD.
+//
+// The result contains the secret key and must be protected
+// accordingly.
+func (a *AEAD) MarshalBinary() ([]byte, error) {
+	if a.destroyed {
+		panic("grain: use after Destroy")
+	}
+	b := make([]byte, marshalSize)
+	b[0] = marshalVersion
+	b[1] = algGrain128AEAD
+	for i, k := range a.s.key {
+		binary.LittleEndian.PutUint32(b[2+4*i:], k)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary replaces a with the AEAD encoded by
+// MarshalBinary.
+func (a *AEAD) UnmarshalBinary(data []byte) error {
+	if len(data) != marshalSize {
+		return errors.New("grain: invalid key blob length")
+	}
+	if data[0] != marshalVersion {
+		return errors.New("grain: unsupported key blob version")
+	}
+	if data[1] != algGrain128AEAD {
+		return errors.New("grain: unknown key blob algorithm")
+	}
+	*a = AEAD{}
+	a.s.setKey(data[2:])
+	return nil
+}
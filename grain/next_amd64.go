@@ -0,0 +1,12 @@
+//go:build gc && !purego && !graindebug
+// +build gc,!purego,!graindebug
+
+package grain
+
+func next(s *state) uint32 {
+	return nextAsm(s)
+}
+
+func accumulate(reg, acc uint64, ms, pt uint16) (uint64, uint64) {
+	return accumulateAsm(reg, acc, ms, pt)
+}
@@ -0,0 +1,23 @@
+//go:build gc && !purego && graindebug
+// +build gc,!purego,graindebug
+
+package grain
+
+// With the graindebug build tag, next and accumulate check
+// forceGeneric so that tests can compare the assembly and
+// generic implementations in the same binary. The check is not
+// free, so it is not present in other builds.
+
+func next(s *state) uint32 {
+	if forceGeneric {
+		return nextGeneric(s)
+	}
+	return nextAsm(s)
+}
+
+func accumulate(reg, acc uint64, ms, pt uint16) (uint64, uint64) {
+	if forceGeneric {
+		return accumulateGeneric(reg, acc, ms, pt)
+	}
+	return accumulateAsm(reg, acc, ms, pt)
+}
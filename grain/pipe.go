@@ -0,0 +1,57 @@
+package grain
+
+import "io"
+
+// pipeBufSize is the size of the buffer used by StreamPipe.
+const pipeBufSize = 32 * 1024
+
+// StreamPipe XORs the Grain128a key stream with data read from
+// an io.Reader.
+//
+// StreamPipe only provides confidentiality. Like
+// NewUnauthenticated, it must not be used to encrypt more than
+// 2^80 bits per key, nonce pair.
+type StreamPipe struct {
+	s   *stream
+	buf []byte
+}
+
+// NewStreamPipe creates a StreamPipe.
+//
+// Decryption is the same operation as encryption, so a
+// StreamPipe created with the same key and nonce decrypts the
+// output of Encrypt.
+func NewStreamPipe(key, nonce []byte) (*StreamPipe, error) {
+	s, err := NewUnauthenticated(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamPipe{s: s.(*stream)}, nil
+}
+
+// Encrypt reads src until EOF, XORs it with the key stream, and
+// writes the result to dst.
+//
+// Successive calls to Encrypt continue the same key stream, as
+// if the data had been read from a single io.Reader.
+func (p *StreamPipe) Encrypt(dst io.Writer, src io.Reader) error {
+	if p.buf == nil {
+		p.buf = make([]byte, pipeBufSize)
+	}
+	for {
+		n, err := src.Read(p.buf)
+		if n > 0 {
+			buf := p.buf[:n]
+			p.s.XORKeyStream(buf, buf)
+			if _, err := dst.Write(buf); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package grain
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamPipe(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+
+	n := 3<<20 + 1
+	if testing.Short() {
+		n = 64<<10 + 1
+	}
+	plaintext := make([]byte, n)
+	rng.Read(plaintext)
+
+	s, err := NewUnauthenticated(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, len(plaintext))
+	s.XORKeyStream(want, plaintext)
+
+	// Read odd-sized pieces across several calls to Encrypt so
+	// that the key stream is split mid-word.
+	p, err := NewStreamPipe(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ct bytes.Buffer
+	for _, chunk := range split(rng, plaintext) {
+		r := iotest.HalfReader(bytes.NewReader(chunk))
+		if err := p.Encrypt(&ct, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(ct.Bytes(), want) {
+		t.Fatal("ciphertext mismatch")
+	}
+
+	p, err = NewStreamPipe(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := p.Encrypt(&got, &ct); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), plaintext) {
+		t.Fatal("plaintext mismatch")
+	}
+}
+
+func TestStreamPipeError(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	p, err := NewStreamPipe(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	r := iotest.TimeoutReader(bytes.NewReader(make([]byte, 10)))
+	if err := p.Encrypt(&buf, r); err != iotest.ErrTimeout {
+		t.Fatalf("expected %v, got %v", iotest.ErrTimeout, err)
+	}
+	if _, err := NewStreamPipe(key, nonce[1:]); err == nil {
+		t.Fatal("expected an error")
+	}
+}
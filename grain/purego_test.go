@@ -0,0 +1,6 @@
+//go:build purego
+// +build purego
+
+package grain
+
+const purego = true
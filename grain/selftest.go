@@ -0,0 +1,89 @@
+package grain
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// selfTestSize is the amount of key stream checked by
+// KeystreamSelfTest.
+const selfTestSize = 4096
+
+var (
+	// selfTestKey and selfTestNonce are the key and nonce used
+	// by KeystreamSelfTest. They match the first test vector.
+	selfTestKey   = []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	selfTestNonce = []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	// selfTestPrefix is the start of the key stream for
+	// selfTestKey and selfTestNonce.
+	selfTestPrefix = []byte{
+		0x2a, 0xb7, 0x13, 0x6e, 0x64, 0xe0, 0xff, 0x14,
+		0x37, 0x72, 0x10, 0x12, 0x51, 0xd3, 0x39, 0x47,
+	}
+)
+
+var (
+	errSelfTestPrefix  = errors.New("grain: self-test: unexpected key stream")
+	errSelfTestBalance = errors.New("grain: self-test: key stream is biased")
+	errSelfTestRun     = errors.New("grain: self-test: key stream has a long run")
+)
+
+// KeystreamSelfTest generates a few KiB of key stream from a
+// fixed key and nonce and checks it for gross defects.
+//
+// It checks that the key stream starts with a known prefix, that
+// about half of its bits are set, and that it has no long runs
+// of identical bits. This catches catastrophic breakage, such as
+// a miscompiled or stuck shift register, and is cheap enough to
+// run at startup on embedded targets.
+//
+// It is not a test of randomness and passing it says nothing
+// about the security of the cipher.
+func KeystreamSelfTest() error {
+	s, err := NewUnauthenticated(selfTestKey, selfTestNonce)
+	if err != nil {
+		return err
+	}
+	ks := make([]byte, selfTestSize)
+	s.XORKeyStream(ks, ks)
+	return checkKeystream(ks)
+}
+
+// checkKeystream performs the checks for KeystreamSelfTest.
+func checkKeystream(ks []byte) error {
+	for i, b := range selfTestPrefix {
+		if i >= len(ks) || ks[i] != b {
+			return errSelfTestPrefix
+		}
+	}
+
+	// The number of set bits should be within a few percent of
+	// half. For 4 KiB that is over 3.5 standard deviations.
+	ones := 0
+	for _, b := range ks {
+		ones += bits.OnesCount8(b)
+	}
+	n := 8 * len(ks)
+	if d := 2*ones - n; d > n/50 || -d > n/50 {
+		return errSelfTestBalance
+	}
+
+	// The longest run of identical bits in 4 KiB of random data
+	// is about 15 bits. 32 bits is very unlikely.
+	const maxRun = 32
+	run, prev := 0, uint(2)
+	for _, b := range ks {
+		for j := 0; j < 8; j++ {
+			bit := uint(b>>j) & 1
+			if bit == prev {
+				run++
+			} else {
+				run, prev = 1, bit
+			}
+			if run >= maxRun {
+				return errSelfTestRun
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package grain
+
+import "errors"
+
+// SplitTag splits the output of Seal into the ciphertext and
+// the tag so that they can be stored apart.
+//
+// Both are sub-slices of sealed. The capacity of ciphertext is
+// limited to its length, so appending to it does not overwrite
+// the tag.
+//
+// SplitTag returns an error if sealed is shorter than TagSize.
+func SplitTag(sealed []byte) (ciphertext, tag []byte, err error) {
+	if len(sealed) < TagSize {
+		return nil, nil, errors.New("grain: sealed message shorter than tag")
+	}
+	n := len(sealed) - TagSize
+	return sealed[:n:n], sealed[n:], nil
+}
@@ -0,0 +1,56 @@
+package grain
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestSplitTag(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+	aead, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ad := []byte("additional data")
+
+	for n := 0; n < 100; n++ {
+		plaintext := make([]byte, n)
+		rng.Read(plaintext)
+		sealed := aead.Seal(nil, nonce, plaintext, ad)
+		ciphertext, tag, err := SplitTag(sealed)
+		if err != nil {
+			t.Fatalf("#%d: %v", n, err)
+		}
+		if len(ciphertext) != n || len(tag) != TagSize {
+			t.Fatalf("#%d: got lengths (%d, %d)", n, len(ciphertext), len(tag))
+		}
+
+		// Rejoining them must open.
+		joined := append(append([]byte(nil), ciphertext...), tag...)
+		got, err := aead.Open(nil, nonce, joined, ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("#%d: expected %#x, got %#x", n, plaintext, got)
+		}
+
+		// Appending to the ciphertext must not clobber the tag.
+		want := append([]byte(nil), tag...)
+		_ = append(ciphertext, 0xff)
+		if !bytes.Equal(tag, want) {
+			t.Fatalf("#%d: tag was overwritten", n)
+		}
+	}
+
+	for n := 0; n < TagSize; n++ {
+		if _, _, err := SplitTag(make([]byte, n)); err == nil {
+			t.Fatalf("#%d: expected an error", n)
+		}
+	}
+}
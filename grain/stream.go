@@ -0,0 +1,163 @@
+package grain
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// STREAMPrefixSize is the size in bytes of a STREAM nonce
+// prefix.
+const STREAMPrefixSize = NonceSize - 5
+
+// maxSTREAMChunks is the maximum number of chunks in a single
+// STREAM.
+const maxSTREAMChunks = math.MaxUint32 + 1
+
+var (
+	errSTREAMDone     = errors.New("grain: STREAM already finished")
+	errSTREAMOverflow = errors.New("grain: STREAM chunk counter overflow")
+	errSTREAMSize     = errors.New("grain: invalid STREAM chunk size")
+)
+
+// STREAM implements the STREAM online authenticated encryption
+// construction from Hoang, Reyhanitabar, and Rogaway using
+// Grain-128AEAD.
+//
+// A message is split into chunks of a fixed size. Each chunk is
+// sealed with the nonce
+//
+//	prefix || BE32(counter) || flag
+//
+// where counter is the index of the chunk and flag is 1 for the
+// final chunk and 0 otherwise. This prevents chunks from being
+// reordered, dropped, or truncated.
+//
+// Grain-128AEAD's 96-bit nonce leaves room for a 56-bit prefix
+// and a 32-bit counter, so a STREAM can contain at most 2^32
+// chunks.
+//
+// Each chunk except the final chunk must contain exactly
+// chunkSize bytes of plaintext. The final chunk may contain
+// between zero and chunkSize bytes of plaintext.
+//
+// A STREAM must be used either to seal or to open a single
+// message, not both. Nonce prefixes must never be reused with
+// the same key.
+//
+// References:
+//
+//	[stream]: https://eprint.iacr.org/2015/189.pdf
+type STREAM struct {
+	aead      AEAD
+	prefix    [STREAMPrefixSize]byte
+	chunkSize int
+	// counter is the index of the next chunk.
+	counter uint64
+	// done is set after the final chunk.
+	done bool
+}
+
+// NewSTREAM creates a STREAM that splits messages into chunks
+// of chunkSize bytes.
+//
+// noncePrefix must be STREAMPrefixSize bytes long. chunkSize
+// must be positive.
+func NewSTREAM(key, noncePrefix []byte, chunkSize int) (*STREAM, error) {
+	if len(noncePrefix) != STREAMPrefixSize {
+		return nil, errors.New("grain: bad STREAM nonce prefix length")
+	}
+	if chunkSize <= 0 {
+		return nil, errors.New("grain: bad STREAM chunk size")
+	}
+	if len(key) != KeySize {
+		return nil, errors.New("grain: bad key length")
+	}
+	s := &STREAM{
+		chunkSize: chunkSize,
+	}
+	s.aead.s.setKey(key)
+	copy(s.prefix[:], noncePrefix)
+	return s, nil
+}
+
+// ChunkSize returns the size in bytes of each plaintext chunk.
+func (s *STREAM) ChunkSize() int {
+	return s.chunkSize
+}
+
+// Overhead returns the difference between the lengths of a
+// plaintext chunk and its ciphertext.
+func (s *STREAM) Overhead() int {
+	return TagSize
+}
+
+// SealChunk encrypts and authenticates the next chunk of
+// plaintext and appends the result to dst, returning the updated
+// slice.
+//
+// last must be true for the final chunk and false otherwise.
+// SealChunk returns an error if the chunk has the wrong size or
+// if the STREAM has already been finished.
+func (s *STREAM) SealChunk(dst, plaintext []byte, last bool) ([]byte, error) {
+	if err := s.check(len(plaintext), last); err != nil {
+		return nil, err
+	}
+	nonce := s.nonce(s.counter, last)
+	s.advance(last)
+	return s.aead.Seal(dst, nonce[:], plaintext, nil), nil
+}
+
+// OpenChunk decrypts and authenticates the next chunk of
+// ciphertext and, if successful, appends the resulting
+// plaintext to dst, returning the updated slice.
+//
+// last must be true for the final chunk and false otherwise. If
+// a chunk fails authentication the STREAM is not advanced.
+func (s *STREAM) OpenChunk(dst, ciphertext []byte, last bool) ([]byte, error) {
+	if len(ciphertext) < TagSize {
+		return nil, errOpen
+	}
+	if err := s.check(len(ciphertext)-TagSize, last); err != nil {
+		return nil, err
+	}
+	nonce := s.nonce(s.counter, last)
+	out, err := s.aead.Open(dst, nonce[:], ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.advance(last)
+	return out, nil
+}
+
+// check reports whether a chunk with n bytes of plaintext can
+// be processed next.
+func (s *STREAM) check(n int, last bool) error {
+	if s.done {
+		return errSTREAMDone
+	}
+	if s.counter >= maxSTREAMChunks {
+		return errSTREAMOverflow
+	}
+	if n > s.chunkSize || (!last && n != s.chunkSize) {
+		return errSTREAMSize
+	}
+	return nil
+}
+
+// advance moves to the next chunk.
+func (s *STREAM) advance(last bool) {
+	s.counter++
+	s.done = last
+}
+
+// nonce returns the nonce for the chunk at index i.
+func (s *STREAM) nonce(i uint64, last bool) [NonceSize]byte {
+	var nonce [NonceSize]byte
+	copy(nonce[:], s.prefix[:])
+	binary.BigEndian.PutUint32(nonce[STREAMPrefixSize:], uint32(i))
+	if last {
+		nonce[NonceSize-1] = 1
+	}
+	return nonce
+}
@@ -0,0 +1,125 @@
+package grain
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// sealSTREAM seals plaintext as a sequence of chunks.
+func sealSTREAM(t *testing.T, s *STREAM, plaintext []byte) [][]byte {
+	t.Helper()
+
+	var chunks [][]byte
+	for {
+		n := len(plaintext)
+		last := n <= s.ChunkSize()
+		if !last {
+			n = s.ChunkSize()
+		}
+		c, err := s.SealChunk(nil, plaintext[:n], last)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, c)
+		plaintext = plaintext[n:]
+		if last {
+			return chunks
+		}
+	}
+}
+
+// openSTREAM opens a sequence of chunks.
+func openSTREAM(s *STREAM, chunks [][]byte) ([]byte, error) {
+	var out []byte
+	for i, c := range chunks {
+		var err error
+		out, err = s.OpenChunk(out, c, i == len(chunks)-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func newTestSTREAM(t *testing.T, chunkSize int) (*STREAM, *STREAM) {
+	t.Helper()
+
+	key := make([]byte, KeySize)
+	prefix := make([]byte, STREAMPrefixSize)
+	rand.Read(key)
+	rand.Read(prefix)
+
+	sealer, err := NewSTREAM(key, prefix, chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener, err := NewSTREAM(key, prefix, chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealer, opener
+}
+
+func TestSTREAMRoundTrip(t *testing.T) {
+	const chunkSize = 64
+	plaintext := make([]byte, 5*chunkSize+1)
+	rand.Read(plaintext)
+
+	for _, n := range []int{
+		0, 1, chunkSize - 1, chunkSize, chunkSize + 1,
+		2 * chunkSize, 5 * chunkSize, 5*chunkSize + 1,
+	} {
+		sealer, opener := newTestSTREAM(t, chunkSize)
+		chunks := sealSTREAM(t, sealer, plaintext[:n])
+		got, err := openSTREAM(opener, chunks)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext[:n]) {
+			t.Fatalf("%d: expected %#x, got %#x", n, plaintext[:n], got)
+		}
+	}
+}
+
+func TestSTREAMReorder(t *testing.T) {
+	sealer, opener := newTestSTREAM(t, 16)
+	chunks := sealSTREAM(t, sealer, make([]byte, 16*3+5))
+	chunks[0], chunks[1] = chunks[1], chunks[0]
+	if _, err := openSTREAM(opener, chunks); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestSTREAMDrop(t *testing.T) {
+	sealer, opener := newTestSTREAM(t, 16)
+	chunks := sealSTREAM(t, sealer, make([]byte, 16*3+5))
+	chunks = append(chunks[:1], chunks[2:]...)
+	if _, err := openSTREAM(opener, chunks); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestSTREAMTruncate(t *testing.T) {
+	sealer, opener := newTestSTREAM(t, 16)
+	chunks := sealSTREAM(t, sealer, make([]byte, 16*3+5))
+	if _, err := openSTREAM(opener, chunks[:len(chunks)-1]); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestSTREAMMisuse(t *testing.T) {
+	sealer, _ := newTestSTREAM(t, 16)
+	if _, err := sealer.SealChunk(nil, make([]byte, 15), false); err != errSTREAMSize {
+		t.Fatalf("expected %v, got %v", errSTREAMSize, err)
+	}
+	if _, err := sealer.SealChunk(nil, make([]byte, 17), true); err != errSTREAMSize {
+		t.Fatalf("expected %v, got %v", errSTREAMSize, err)
+	}
+	if _, err := sealer.SealChunk(nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sealer.SealChunk(nil, nil, true); err != errSTREAMDone {
+		t.Fatalf("expected %v, got %v", errSTREAMDone, err)
+	}
+}
@@ -5,7 +5,7 @@
 package grain
 
 //go:noescape
-func next(s *state) uint32
+func nextAsm(s *state) uint32
 
 //go:noescape
-func accumulate(reg uint64, acc uint64, ms uint16, pt uint16) (reg1 uint64, acc1 uint64)
+func accumulateAsm(reg uint64, acc uint64, ms uint16, pt uint16) (reg1 uint64, acc1 uint64)
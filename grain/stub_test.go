@@ -0,0 +1,83 @@
+package grain
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math/rand"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// stubs calls each function declared in the assembly stubs and
+// its generic counterpart with the same inputs and reports
+// whether the results match.
+var stubs = map[string]func(s state, x, y uint16) bool{
+	"nextAsm": func(s state, _, _ uint16) bool {
+		want, got := s, s
+		return nextGeneric(&want) == next(&got) && want == got
+	},
+	"accumulateAsm": func(s state, x, y uint16) bool {
+		want, got := s, s
+		want.reg, want.acc = accumulateGeneric(s.reg, s.acc, x, y)
+		got.reg, got.acc = accumulate(s.reg, s.acc, x, y)
+		return equalAuthState(&want, &got)
+	},
+}
+
+func TestImplementation(t *testing.T) {
+	switch impl := Implementation(); impl {
+	case "generic":
+		if _, err := os.Stat("stub_" + runtime.GOARCH + ".go"); err == nil && !purego {
+			t.Fatalf("expected %q, got %q", runtime.GOARCH, impl)
+		}
+	case runtime.GOARCH:
+		if purego {
+			t.Fatalf("expected %q, got %q", "generic", impl)
+		}
+	default:
+		t.Fatalf("unexpected implementation: %q", impl)
+	}
+}
+
+// TestStubs is a smoke test for the functions declared in the
+// assembly stubs.
+//
+// It calls each function once on the current GOARCH, so a stub
+// without an implementation fails to link, and checks that
+// stubs covers every function declared in stub_$GOARCH.go.
+func TestStubs(t *testing.T) {
+	rng := rand.New(rand.NewSource(0xDEADBEEF))
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	rng.Read(key)
+	rng.Read(nonce)
+
+	var s state
+	s.setKey(key)
+	s.init(nonce)
+	for name, fn := range stubs {
+		if !fn(s, uint16(rng.Uint32()), uint16(rng.Uint32())) {
+			t.Fatalf("%s: mismatch with generic implementation", name)
+		}
+	}
+
+	path := "stub_" + runtime.GOARCH + ".go"
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		t.Skipf("no stubs for %s", runtime.GOARCH)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body != nil {
+			continue
+		}
+		if _, ok := stubs[fn.Name.Name]; !ok {
+			t.Errorf("%s: %s is not tested", path, fn.Name.Name)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package kat generates known answer test vectors in the
+// layout of the NIST Lightweight Cryptography project.
+package kat
+
+import "crypto/cipher"
+
+// Entry is a known answer test vector.
+type Entry struct {
+	// Count is the one-based index of the vector.
+	Count int
+	Key   []byte
+	Nonce []byte
+	PT    []byte
+	AD    []byte
+	// CT is the ciphertext followed by the tag.
+	CT []byte
+}
+
+// MaxAD is the maximum length of the additional data in the
+// LWC known answer tests.
+const MaxAD = 32
+
+// Generate returns count vectors for aead, which must use the
+// key Incrementing(keySize).
+//
+// The vectors follow genkat_aead.c: the nonce, plaintext, and
+// additional data are the bytes 0, 1, 2, and so on, and vector
+// i has (i-1)/33 bytes of plaintext and (i-1)%33 bytes of
+// additional data.
+func Generate(aead cipher.AEAD, keySize, count int) []Entry {
+	if count <= 0 {
+		return nil
+	}
+	vecs := make([]Entry, count)
+	for i := range vecs {
+		v := Entry{
+			Count: i + 1,
+			Key:   Incrementing(keySize),
+			Nonce: Incrementing(aead.NonceSize()),
+			PT:    Incrementing(i / (MaxAD + 1)),
+			AD:    Incrementing(i % (MaxAD + 1)),
+		}
+		v.CT = aead.Seal(nil, v.Nonce, v.PT, v.AD)
+		vecs[i] = v
+	}
+	return vecs
+}
+
+// Incrementing returns the n bytes 0, 1, 2, ...
+func Incrementing(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
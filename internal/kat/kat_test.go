@@ -0,0 +1,57 @@
+package kat
+
+import (
+	"bytes"
+	"testing"
+)
+
+// lengths is a cipher.AEAD whose ciphertext is the plaintext
+// followed by the lengths of the nonce and additional data.
+type lengths struct{}
+
+func (lengths) NonceSize() int { return 12 }
+func (lengths) Overhead() int  { return 2 }
+
+func (lengths) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	dst = append(dst, plaintext...)
+	return append(dst, byte(len(nonce)), byte(len(additionalData)))
+}
+
+func (lengths) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	panic("unused")
+}
+
+func TestGenerate(t *testing.T) {
+	const count = 33*33 + 40
+	vecs := Generate(lengths{}, 16, count)
+	if len(vecs) != count {
+		t.Fatalf("expected %d vectors, got %d", count, len(vecs))
+	}
+	for i, v := range vecs {
+		if v.Count != i+1 {
+			t.Fatalf("#%d: expected count %d, got %d", i+1, i+1, v.Count)
+		}
+		pt := Incrementing(i / 33)
+		ad := Incrementing(i % 33)
+		for _, f := range []struct {
+			name      string
+			want, got []byte
+		}{
+			{"Key", Incrementing(16), v.Key},
+			{"Nonce", Incrementing(12), v.Nonce},
+			{"PT", pt, v.PT},
+			{"AD", ad, v.AD},
+			{"CT", append(pt, 12, byte(len(ad))), v.CT},
+		} {
+			if !bytes.Equal(f.want, f.got) {
+				t.Fatalf("#%d: %s: expected %#x, got %#x", i+1, f.name, f.want, f.got)
+			}
+		}
+	}
+
+	for _, n := range []int{0, -1} {
+		if vecs := Generate(lengths{}, 16, n); vecs != nil {
+			t.Fatalf("%d: expected nil, got %v", n, vecs)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package lwcrypto
+
+import (
+	"crypto/cipher"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrKeyExhausted is returned by MessageLimited.Seal once the
+// key has sealed the maximum number of messages. The caller
+// should switch to a new key.
+var ErrKeyExhausted = errors.New("lwcrypto: key exhausted")
+
+// MessageLimited wraps an AEAD and limits the number of messages
+// it seals.
+//
+// It complements limits on the number of bytes processed with a
+// key. It is safe for concurrent use if the underlying AEAD is.
+type MessageLimited struct {
+	// n is the number of messages sealed so far. It is accessed
+	// atomically and must be 64-bit aligned, so it comes first.
+	n    uint64
+	max  uint64
+	aead cipher.AEAD
+}
+
+// NewMessageLimited creates a MessageLimited that seals at most
+// maxMessages messages with aead.
+func NewMessageLimited(aead cipher.AEAD, maxMessages uint64) *MessageLimited {
+	return &MessageLimited{max: maxMessages, aead: aead}
+}
+
+// NonceSize returns the size of the nonce that must be passed
+// to Seal and Open.
+func (m *MessageLimited) NonceSize() int {
+	return m.aead.NonceSize()
+}
+
+// Overhead returns the maximum difference between the lengths
+// of a plaintext and its ciphertext.
+func (m *MessageLimited) Overhead() int {
+	return m.aead.Overhead()
+}
+
+// Seal is like cipher.AEAD.Seal, but returns ErrKeyExhausted
+// without encrypting anything once maxMessages messages have
+// been sealed.
+func (m *MessageLimited) Seal(dst, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	for {
+		n := atomic.LoadUint64(&m.n)
+		if n >= m.max {
+			return nil, ErrKeyExhausted
+		}
+		if atomic.CompareAndSwapUint64(&m.n, n, n+1) {
+			break
+		}
+	}
+	return m.aead.Seal(dst, nonce, plaintext, additionalData), nil
+}
+
+// Open is the same as cipher.AEAD.Open. It is not limited.
+func (m *MessageLimited) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	return m.aead.Open(dst, nonce, ciphertext, additionalData)
+}
+
+// Sealed returns the number of messages sealed so far.
+func (m *MessageLimited) Sealed() uint64 {
+	return atomic.LoadUint64(&m.n)
+}
@@ -0,0 +1,86 @@
+package lwcrypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"sync"
+	"testing"
+
+	"github.com/ericlagergren/lwcrypto/ascon"
+)
+
+func TestMessageLimited(t *testing.T) {
+	const max = 10
+
+	key := make([]byte, ascon.KeySize)
+	nonce := make([]byte, ascon.NonceSize)
+	rand.Read(key)
+	rand.Read(nonce)
+	aead, err := ascon.New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMessageLimited(aead, max)
+	plaintext := []byte("hello, world!")
+
+	var ciphertext []byte
+	for i := 0; i < max; i++ {
+		ciphertext, err = m.Seal(nil, nonce, plaintext, nil)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+	}
+	if _, err := m.Seal(nil, nonce, plaintext, nil); err != ErrKeyExhausted {
+		t.Fatalf("expected %v, got %v", ErrKeyExhausted, err)
+	}
+	if n := m.Sealed(); n != max {
+		t.Fatalf("expected %d, got %d", max, n)
+	}
+
+	// Open is not limited.
+	for i := 0; i < 2*max; i++ {
+		got, err := m.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("#%d: expected %#x, got %#x", i, plaintext, got)
+		}
+	}
+}
+
+func TestMessageLimitedConcurrent(t *testing.T) {
+	const (
+		max     = 100
+		workers = 8
+	)
+
+	key := make([]byte, ascon.KeySize)
+	nonce := make([]byte, ascon.NonceSize)
+	aead, err := ascon.New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMessageLimited(aead, max)
+
+	var mu sync.Mutex
+	sealed := 0
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < max; j++ {
+				if _, err := m.Seal(nil, nonce, nil, nil); err == nil {
+					mu.Lock()
+					sealed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if sealed != max {
+		t.Fatalf("expected %d, got %d", max, sealed)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package lwcrypto
+
+// raceEnabled is set when the race detector is enabled, which
+// makes sync.Pool drop items at random.
+const raceEnabled = true
@@ -0,0 +1,80 @@
+package lwcrypto
+
+import (
+	"crypto/cipher"
+	"sync"
+)
+
+// ReceiverNonceTracker wraps an AEAD and detects nonces that
+// the sender has reused.
+//
+// A reused nonce usually means that the sender is buggy or that
+// someone is replaying messages. ReceiverNonceTracker only
+// reports reuse through OnReuse; it does not reject the
+// message, since a legitimate retransmission also repeats a
+// nonce.
+//
+// Only nonces of messages that open successfully are recorded,
+// so forged messages cannot trigger false reports. Every such
+// nonce is kept in memory for the lifetime of the tracker.
+//
+// It is safe for concurrent use if the underlying AEAD is.
+type ReceiverNonceTracker struct {
+	aead cipher.AEAD
+
+	// OnReuse, if non-nil, is called the first time a nonce is
+	// seen for the second time. It must not retain nonce.
+	//
+	// OnReuse is called while holding a lock, so it must not
+	// call Open.
+	OnReuse func(nonce []byte)
+
+	mu sync.Mutex
+	// seen maps each nonce to whether it has been reported.
+	seen map[string]bool
+}
+
+// NewReceiverNonceTracker creates a ReceiverNonceTracker.
+func NewReceiverNonceTracker(aead cipher.AEAD) *ReceiverNonceTracker {
+	return &ReceiverNonceTracker{
+		aead: aead,
+		seen: make(map[string]bool),
+	}
+}
+
+// NonceSize returns the size of the nonce that must be passed
+// to Seal and Open.
+func (r *ReceiverNonceTracker) NonceSize() int {
+	return r.aead.NonceSize()
+}
+
+// Overhead returns the maximum difference between the lengths
+// of a plaintext and its ciphertext.
+func (r *ReceiverNonceTracker) Overhead() int {
+	return r.aead.Overhead()
+}
+
+// Open is like cipher.AEAD.Open, but also records nonce if the
+// ciphertext is authentic.
+func (r *ReceiverNonceTracker) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	out, err := r.aead.Open(dst, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reported, ok := r.seen[string(nonce)]
+	if !ok {
+		r.seen[string(nonce)] = false
+		return out, nil
+	}
+	if !reported {
+		r.seen[string(nonce)] = true
+		if r.OnReuse != nil {
+			r.OnReuse(nonce)
+		}
+	}
+	return out, nil
+}
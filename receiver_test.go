@@ -0,0 +1,68 @@
+package lwcrypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/ericlagergren/lwcrypto/grain"
+)
+
+func TestReceiverNonceTracker(t *testing.T) {
+	key := make([]byte, grain.KeySize)
+	rand.Read(key)
+	aead, err := grain.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reused [][]byte
+	r := NewReceiverNonceTracker(aead)
+	r.OnReuse = func(nonce []byte) {
+		reused = append(reused, append([]byte(nil), nonce...))
+	}
+
+	plaintext := []byte("hello, world!")
+	n1 := make([]byte, aead.NonceSize())
+	n2 := make([]byte, aead.NonceSize())
+	rand.Read(n1)
+	rand.Read(n2)
+	c1 := aead.Seal(nil, n1, plaintext, nil)
+	c2 := aead.Seal(nil, n2, plaintext, nil)
+
+	open := func(nonce, ciphertext []byte) {
+		t.Helper()
+
+		got, err := r.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("expected %#x, got %#x", plaintext, got)
+		}
+	}
+
+	open(n1, c1)
+	open(n2, c2)
+	if len(reused) != 0 {
+		t.Fatalf("unexpected reuse: %#x", reused)
+	}
+
+	// The repeat is reported exactly once.
+	open(n1, c1)
+	open(n1, c1)
+	open(n1, c1)
+	if len(reused) != 1 || !bytes.Equal(reused[0], n1) {
+		t.Fatalf("expected %#x, got %#x", [][]byte{n1}, reused)
+	}
+
+	// Forgeries are not recorded.
+	forged := append([]byte(nil), c2...)
+	forged[0] ^= 1
+	if _, err := r.Open(nil, n2, forged, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(reused) != 1 {
+		t.Fatalf("unexpected reuse: %#x", reused)
+	}
+}
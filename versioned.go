@@ -0,0 +1,185 @@
+// Package lwcrypto contains utilities that work with any of the
+// module's AEADs.
+package lwcrypto
+
+import (
+	"crypto/cipher"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/ericlagergren/subtle"
+)
+
+var errVersion = errors.New("lwcrypto: unknown or mismatched version")
+
+// Versioned is a cipher.AEAD that prefixes each ciphertext with
+// a one-byte algorithm or version identifier.
+//
+// The identifier makes stored ciphertexts self-describing, so
+// that data can be migrated to a new algorithm later without
+// ambiguity. See Register and OpenVersioned.
+//
+// The identifier is authenticated: it is prepended to the
+// additional data passed to the underlying AEAD, so a ciphertext
+// re-tagged with a different identifier fails to open even if
+// both identifiers use the same algorithm and key.
+type Versioned struct {
+	id   byte
+	aead cipher.AEAD
+}
+
+var _ cipher.AEAD = (*Versioned)(nil)
+
+// NewVersioned creates a Versioned AEAD that tags ciphertexts
+// with id.
+func NewVersioned(id byte, aead cipher.AEAD) *Versioned {
+	return &Versioned{id: id, aead: aead}
+}
+
+// ID returns the identifier.
+func (v *Versioned) ID() byte {
+	return v.id
+}
+
+func (v *Versioned) NonceSize() int {
+	return v.aead.NonceSize()
+}
+
+func (v *Versioned) Overhead() int {
+	return 1 + v.aead.Overhead()
+}
+
+func (v *Versioned) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, v.Overhead()+len(plaintext))
+	if subtle.AnyOverlap(out, plaintext) {
+		if subtle.InexactOverlap(out, plaintext) {
+			panic("lwcrypto: invalid buffer overlap")
+		}
+		// The plaintext is being sealed in place, so move it
+		// past the identifier first.
+		copy(out[1:], plaintext)
+		plaintext = out[1 : 1+len(plaintext)]
+	}
+	out[0] = v.id
+	ad := v.ad(additionalData)
+	v.aead.Seal(out[1:1], nonce, plaintext, *ad)
+	putAD(ad)
+	return ret
+}
+
+func (v *Versioned) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < 1 || ciphertext[0] != v.id {
+		return nil, errVersion
+	}
+	ad := v.ad(additionalData)
+	defer putAD(ad)
+
+	n := len(ciphertext) - v.Overhead()
+	if n >= 0 && cap(dst)-len(dst) >= n {
+		out := dst[len(dst) : len(dst)+n]
+		if subtle.AnyOverlap(out, ciphertext) && !subtle.InexactOverlap(out, ciphertext) {
+			// The ciphertext is being opened in place. Open
+			// it just past the identifier, then move the
+			// plaintext down.
+			pt, err := v.aead.Open(ciphertext[1:1], nonce, ciphertext[1:], *ad)
+			if err != nil {
+				return nil, err
+			}
+			copy(out, pt)
+			return dst[:len(dst)+n], nil
+		}
+	}
+	return v.aead.Open(dst, nonce, ciphertext[1:], *ad)
+}
+
+// adPool reuses the buffers returned by ad.
+var adPool = sync.Pool{
+	New: func() interface{} {
+		return new([]byte)
+	},
+}
+
+// maxPooledAD is the largest buffer kept in adPool, so that one
+// large message does not pin its memory.
+const maxPooledAD = 4096
+
+// putAD returns a buffer from ad to adPool.
+func putAD(ad *[]byte) {
+	if cap(*ad) <= maxPooledAD {
+		adPool.Put(ad)
+	}
+}
+
+// ad returns id || additionalData, which binds the identifier
+// to the ciphertext.
+//
+// The result comes from adPool and should be returned with
+// putAD.
+func (v *Versioned) ad(additionalData []byte) *[]byte {
+	ad := adPool.Get().(*[]byte)
+	*ad = append(append((*ad)[:0], v.id), additionalData...)
+	return ad
+}
+
+var registry struct {
+	sync.RWMutex
+	m map[byte]func(key []byte) (cipher.AEAD, error)
+}
+
+// Register associates id with an AEAD constructor, such as
+// ascon.New128, for use with OpenVersioned and NewRegistered.
+//
+// Register panics if id has already been registered. It is
+// typically called from an init function.
+func Register(id byte, fn func(key []byte) (cipher.AEAD, error)) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.m[id]; ok {
+		panic("lwcrypto: version already registered: " + strconv.Itoa(int(id)))
+	}
+	if registry.m == nil {
+		registry.m = make(map[byte]func([]byte) (cipher.AEAD, error))
+	}
+	registry.m[id] = fn
+}
+
+// lookup returns the constructor registered for id.
+func lookup(id byte) (func([]byte) (cipher.AEAD, error), bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	fn, ok := registry.m[id]
+	return fn, ok
+}
+
+// NewRegistered creates a Versioned AEAD using the constructor
+// registered for id.
+func NewRegistered(id byte, key []byte) (*Versioned, error) {
+	fn, ok := lookup(id)
+	if !ok {
+		return nil, errVersion
+	}
+	aead, err := fn(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewVersioned(id, aead), nil
+}
+
+// OpenVersioned opens a ciphertext sealed by a Versioned AEAD
+// using the constructor registered for its identifier.
+func OpenVersioned(key, dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < 1 {
+		return nil, errVersion
+	}
+	v, err := NewRegistered(ciphertext[0], key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != v.NonceSize() {
+		return nil, errors.New("lwcrypto: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+	}
+	return v.Open(dst, nonce, ciphertext, additionalData)
+}
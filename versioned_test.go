@@ -0,0 +1,184 @@
+package lwcrypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/ericlagergren/lwcrypto/ascon"
+	"github.com/ericlagergren/lwcrypto/grain"
+)
+
+func init() {
+	Register(1, ascon.New128)
+	Register(2, ascon.New128a)
+	Register(3, grain.New)
+}
+
+func TestVersioned(t *testing.T) {
+	key := make([]byte, 16)
+	rand.Read(key)
+	plaintext := []byte("hello, world!")
+	ad := []byte("additional data")
+
+	var sealed [][]byte
+	var nonces [][]byte
+	for _, id := range []byte{1, 2, 3} {
+		v, err := NewRegistered(id, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Overhead() != 1+v.aead.Overhead() {
+			t.Fatalf("%d: unexpected overhead: %d", id, v.Overhead())
+		}
+		nonce := make([]byte, v.NonceSize())
+		rand.Read(nonce)
+		ct := v.Seal(nil, nonce, plaintext, ad)
+		if ct[0] != id {
+			t.Fatalf("%d: expected version %d, got %d", id, id, ct[0])
+		}
+		if len(ct) != len(plaintext)+v.Overhead() {
+			t.Fatalf("%d: expected %d bytes, got %d",
+				id, len(plaintext)+v.Overhead(), len(ct))
+		}
+		got, err := v.Open(nil, nonce, ct, ad)
+		if err != nil {
+			t.Fatalf("%d: %v", id, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("%d: expected %#x, got %#x", id, plaintext, got)
+		}
+		got, err = OpenVersioned(key, nil, nonce, ct, ad)
+		if err != nil {
+			t.Fatalf("%d: %v", id, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("%d: expected %#x, got %#x", id, plaintext, got)
+		}
+		sealed = append(sealed, ct)
+		nonces = append(nonces, nonce)
+	}
+
+	// A ciphertext does not open under a different version.
+	a, err := ascon.New128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []byte{0, 2, 3, 255} {
+		v := NewVersioned(id, a)
+		if _, err := v.Open(nil, nonces[0], sealed[0], ad); err != errVersion {
+			t.Fatalf("%d: expected %v, got %v", id, errVersion, err)
+		}
+	}
+
+	// Changing the version byte dispatches to a different
+	// algorithm, which fails.
+	ct := append([]byte(nil), sealed[0]...)
+	ct[0] = 2
+	if _, err := OpenVersioned(key, nil, nonces[0], ct, ad); err == nil {
+		t.Fatal("expected an error")
+	}
+	ct[0] = 4
+	if _, err := OpenVersioned(key, nil, nonces[0], ct, ad); err != errVersion {
+		t.Fatalf("expected %v, got %v", errVersion, err)
+	}
+	if _, err := OpenVersioned(key, nil, nonces[0], nil, ad); err != errVersion {
+		t.Fatalf("expected %v, got %v", errVersion, err)
+	}
+
+	// The version byte is authenticated, so re-tagging a
+	// ciphertext for a second version that wraps the same AEAD
+	// fails.
+	v1 := NewVersioned(1, a)
+	v5 := NewVersioned(5, a)
+	ct = v1.Seal(nil, nonces[0], plaintext, ad)
+	ct[0] = 5
+	if _, err := v5.Open(nil, nonces[0], ct, ad); err == nil {
+		t.Fatal("expected an error")
+	}
+	ct[0] = 1
+	if _, err := v1.Open(nil, nonces[0], ct, ad); err != nil {
+		t.Fatal(err)
+	}
+
+	// Grain uses a different nonce size.
+	if _, err := OpenVersioned(key, nil, nonces[0], sealed[2], ad); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	Register(1, ascon.New128)
+}
+
+func TestVersionedInPlace(t *testing.T) {
+	key := make([]byte, 16)
+	rand.Read(key)
+	plaintext := []byte("hello, world!")
+	ad := []byte("additional data")
+
+	for _, id := range []byte{1, 2, 3} {
+		v, err := NewRegistered(id, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := make([]byte, v.NonceSize())
+		rand.Read(nonce)
+		want := v.Seal(nil, nonce, plaintext, ad)
+
+		buf := make([]byte, len(plaintext), len(plaintext)+v.Overhead())
+		copy(buf, plaintext)
+		ct := v.Seal(buf[:0], nonce, buf, ad)
+		if !bytes.Equal(ct, want) {
+			t.Fatalf("%d: expected %#x, got %#x", id, want, ct)
+		}
+
+		pt, err := v.Open(ct[:0], nonce, ct, ad)
+		if err != nil {
+			t.Fatalf("%d: %v", id, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("%d: expected %#x, got %#x", id, plaintext, pt)
+		}
+
+		// Inexact overlap is rejected.
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%d: expected a panic", id)
+				}
+			}()
+			buf := make([]byte, len(plaintext)+v.Overhead()+1)
+			v.Seal(buf[:0], nonce, buf[1:1+len(plaintext)], ad)
+		}()
+	}
+}
+
+func TestVersionedAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+	key := make([]byte, 16)
+	v, err := NewRegistered(1, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, v.NonceSize())
+	plaintext := make([]byte, 64)
+	ad := make([]byte, 32)
+	buf := make([]byte, 0, len(plaintext)+v.Overhead())
+	n := testing.AllocsPerRun(100, func() {
+		ct := v.Seal(buf[:0], nonce, plaintext, ad)
+		if _, err := v.Open(ct[:0], nonce, ct, ad); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if n > 0 {
+		t.Fatalf("expected 0 allocations, got %.1f", n)
+	}
+}